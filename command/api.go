@@ -0,0 +1,38 @@
+package command
+
+import (
+	"encoding/xml"
+
+	"github.com/tsukaeru/pacicli/lib"
+)
+
+// apiError is returned by the API helper functions when the API responds with
+// an error status code. The error message is the response body
+type apiError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *apiError) Error() string {
+	return e.Body
+}
+
+// getResource sends GET request to path and unmarshals the response into v.
+// Unlike the command functions, it returns an error instead of exiting so that
+// it can be used in goroutines
+func getResource(path string, v interface{}) error {
+	resp, err := client.SendRequest("GET", path, nil)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 400 {
+		return &apiError{StatusCode: resp.StatusCode, Body: string(resp.Body)}
+	}
+	return xml.Unmarshal(resp.Body, v)
+}
+
+func getVe(vename string) (lib.Ve, error) {
+	ve := lib.Ve{}
+	err := getResource("/ve/"+vename, &ve)
+	return ve, err
+}
@@ -3,7 +3,6 @@ package command
 import (
 	"bytes"
 	"encoding/xml"
-	"fmt"
 
 	"github.com/codegangsta/cli"
 	"github.com/tatsushid/go-prettytable"
@@ -18,7 +17,7 @@ var commandApplicationList = cli.Command{
 	This command obtains a list of the available application templates for
 	Container.
 `,
-	Flags: append(CommonFlags, noHeaderFlag),
+	Flags: append(CommonFlags, showUnknownFlag, filterFlag, noHeaderFlag),
 	Action: func(c *cli.Context) {
 		action(c, doApplicationList)
 	},
@@ -35,7 +34,7 @@ var commandApplicationInfo = cli.Command{
 	together uniquely identify an application template. There could be multiple
 	templates with the same name but designed for different operating systems.
 `,
-	Flags: CommonFlags,
+	Flags: append(CommonFlags, showUnknownFlag),
 	Action: func(c *cli.Context) {
 		action(c, doApplicationInfo)
 	},
@@ -51,7 +50,7 @@ var commandApplicationInstall = cli.Command{
 
 	You can specify multiple application templates in this command call.
 `,
-	Flags: CommonFlags,
+	Flags: append(CommonFlags, selectorFlag),
 	Action: func(c *cli.Context) {
 		action(c, doApplicationInstall)
 	},
@@ -67,7 +66,7 @@ var commandApplicationReset = cli.Command{
 	the will be installed. All other installed application templates will be
 	removed from the server.
 `,
-	Flags: CommonFlags,
+	Flags: append(CommonFlags, selectorFlag),
 	Action: func(c *cli.Context) {
 		action(c, doApplicationReset)
 	},
@@ -80,7 +79,7 @@ var commandApplicationDelete = cli.Command{
 	This command removes an application template from a Container. The <app_name>
 	argument must contain the application name.
 `,
-	Flags: CommonFlags,
+	Flags: append(CommonFlags, selectorFlag),
 	Action: func(c *cli.Context) {
 		action(c, doApplicationDelete)
 	},
@@ -106,7 +105,7 @@ var commandOSList = cli.Command{
 	it is included, only the information about the specified template will be
 	retrieved.
 `,
-	Flags: append(CommonFlags, verboseFlag, noHeaderFlag),
+	Flags: append(CommonFlags, showUnknownFlag, filterFlag, verboseFlag, noHeaderFlag),
 	Action: func(c *cli.Context) {
 		action(c, doOSList)
 	},
@@ -121,18 +120,15 @@ func doApplicationList(c *cli.Context) {
 	if resp.StatusCode >= 400 {
 		displayErrorAndExit(string(resp.Body))
 	}
+	applyListFilters(c, &applist.ApplicationTemplate)
 
-	outputResult(c, applist, func(format string) {
-		tbl, err := prettytable.NewTable([]prettytable.Column{
+	outputResponse(c, resp, applist, func(format string) {
+		tbl := newTable(c, []prettytable.Column{
 			{Header: "ID", AlignRight: true},
 			{Header: "NAME"},
 			{Header: "FOROS"},
 			{Header: "DESCRIPTION"},
 		}...)
-		assert(err)
-		if c.Bool("no-header") {
-			tbl.NoHeader = true
-		}
 		for _, e := range applist.ApplicationTemplate {
 			tbl.AddRow(e.ID, e.Name, e.ForOS, e.Description)
 		}
@@ -156,21 +152,22 @@ func doApplicationInfo(c *cli.Context) {
 	app := lib.ApplicationTemplate{}
 	assert(xml.Unmarshal(resp.Body, &app))
 
-	outputResult(c, app, func(format string) {
+	outputResponse(c, resp, app, func(format string) {
 		lib.PrintXMLStruct(app)
 	})
 }
 
 func doApplicationInstall(c *cli.Context) {
-	if len(c.Args()) < 2 {
+	args := serverArgs(c, 0)
+	if len(args) < 2 {
 		displayWrongNumOfArgsAndExit(c)
 	}
 
-	path := "/ve/" + c.Args().Get(0) + "/install"
-	if len(c.Args()) == 2 {
-		path += "/" + c.Args().Get(1)
+	path := "/ve/" + args.Get(0) + "/install"
+	if len(args) == 2 {
+		path += "/" + args.Get(1)
 	} else {
-		for i, e := range c.Args()[1:] {
+		for i, e := range args[1:] {
 			if i == 0 {
 				path += "?"
 			} else {
@@ -183,21 +180,19 @@ func doApplicationInstall(c *cli.Context) {
 	resp, err := client.SendRequest("PUT", path, nil)
 	assert(err)
 
-	switch resp.StatusCode {
-	case 202:
-		fmt.Println(string(resp.Body))
-	default:
-		displayErrorAndExit(string(resp.Body))
-	}
+	r, err := newActionResult(args.Get(0), c.Command.Name, resp, 202)
+	assert(err)
+	outputActionResult(c, r)
 }
 
 func doApplicationReset(c *cli.Context) {
-	if len(c.Args()) < 2 {
+	args := serverArgs(c, 0)
+	if len(args) < 2 {
 		displayWrongNumOfArgsAndExit(c)
 	}
 
-	path := "/ve/" + c.Args().Get(0) + "/application"
-	for i, e := range c.Args()[1:] {
+	path := "/ve/" + args.Get(0) + "/application"
+	for i, e := range args[1:] {
 		if i == 0 {
 			path += "?"
 		} else {
@@ -209,31 +204,26 @@ func doApplicationReset(c *cli.Context) {
 	resp, err := client.SendRequest("POST", path, nil)
 	assert(err)
 
-	switch resp.StatusCode {
-	case 202:
-		fmt.Println(string(resp.Body))
-	default:
-		displayErrorAndExit(string(resp.Body))
-	}
+	r, err := newActionResult(args.Get(0), c.Command.Name, resp, 202)
+	assert(err)
+	outputActionResult(c, r)
 }
 
 func doApplicationDelete(c *cli.Context) {
-	if len(c.Args()) < 2 {
+	args := serverArgs(c, 0)
+	if len(args) < 2 {
 		displayWrongNumOfArgsAndExit(c)
 	}
 
-	vename := c.Args().Get(0)
-	appname := c.Args().Get(1)
+	vename := args.Get(0)
+	appname := args.Get(1)
 
 	resp, err := client.SendRequest("DELETE", "/ve/"+vename+"/application/"+appname, nil)
 	assert(err)
 
-	switch resp.StatusCode {
-	case 202:
-		fmt.Println(string(resp.Body))
-	default:
-		displayErrorAndExit(string(resp.Body))
-	}
+	r, err := newActionResult(vename+"/"+appname, c.Command.Name, resp, 202)
+	assert(err)
+	outputActionResult(c, r)
 }
 
 func doOSList(c *cli.Context) {
@@ -251,17 +241,14 @@ func doOSList(c *cli.Context) {
 	if bytes.Contains(resp.Body, []byte("template-list")) {
 		tmpls := lib.TemplateList{}
 		assert(xml.Unmarshal(resp.Body, &tmpls))
-		outputResult(c, tmpls, func(format string) {
+		applyListFilters(c, &tmpls.Template)
+		outputResponse(c, resp, tmpls, func(format string) {
 			if c.Bool("verbose") {
 				lib.PrintXMLStruct(tmpls)
 			} else {
-				tbl, err := prettytable.NewTable([]prettytable.Column{
+				tbl := newTable(c, []prettytable.Column{
 					{Header: "TEMPLATE_NAME"}, {Header: "TECHNOLOGY"}, {Header: "TYPE"},
 				}...)
-				assert(err)
-				if c.Bool("no-header") {
-					tbl.NoHeader = true
-				}
 				for _, e := range tmpls.Template {
 					tbl.AddRow(e.Name, e.Technology, e.OSType)
 				}
@@ -271,17 +258,13 @@ func doOSList(c *cli.Context) {
 	} else {
 		tmpl := lib.Template{}
 		assert(xml.Unmarshal(resp.Body, &tmpl))
-		outputResult(c, tmpl, func(format string) {
+		outputResponse(c, resp, tmpl, func(format string) {
 			if c.Bool("verbose") {
 				lib.PrintXMLStruct(tmpl)
 			} else {
-				tbl, err := prettytable.NewTable([]prettytable.Column{
+				tbl := newTable(c, []prettytable.Column{
 					{Header: "TEMPLATE_NAME"}, {Header: "TECHNOLOGY"}, {Header: "TYPE"},
 				}...)
-				assert(err)
-				if c.Bool("no-header") {
-					tbl.NoHeader = true
-				}
 				tbl.AddRow(tmpl.Name, tmpl.Technology, tmpl.OSType)
 				tbl.Print()
 			}
@@ -18,7 +18,7 @@ var commandAutoscale = cli.Command{
 	Description: `
 	This command obtains auto scaling rules for the specified server
 `,
-	Flags: CommonFlags,
+	Flags: append(CommonFlags, showUnknownFlag, selectorFlag),
 	Action: func(c *cli.Context) {
 		action(c, doAutoscale)
 	},
@@ -31,7 +31,7 @@ var commandAutoscaleCreate = cli.Command{
 	This command creates auto scaling rules for the specified server using rules
 	specified in Pacicli or --setting-file flag argument file
 `,
-	Flags: append(CommonFlags, settingFlag),
+	Flags: append(CommonFlags, settingFlag, selectorFlag),
 	Action: func(c *cli.Context) {
 		action(c, doAutoscaleCreateUpdate)
 	},
@@ -44,7 +44,7 @@ var commandAutoscaleUpdate = cli.Command{
 	This command updates existing auto scaling rules for the specified server
 	using rules specified in Pacicli or --setting-file flag argument file
 `,
-	Flags: append(CommonFlags, settingFlag),
+	Flags: append(CommonFlags, settingFlag, selectorFlag),
 	Action: func(c *cli.Context) {
 		action(c, doAutoscaleCreateUpdate)
 	},
@@ -55,8 +55,9 @@ var commandAutoscaleDrop = cli.Command{
 	Usage: "Drop auto scaling rules from Container/Virtual machine",
 	Description: `
 	This command drops auto scaling rules from the specified server
-`,
-	Flags: CommonFlags,
+
+` + confirmHelp,
+	Flags: append(CommonFlags, yesFlag, selectorFlag),
 	Action: func(c *cli.Context) {
 		action(c, doAutoscaleDrop)
 	},
@@ -92,17 +93,18 @@ var commandAutoscaleHistory = cli.Command{
 	which may significantly slow down the processing of the command call. Using
 	the averaging approach, you can avoid this potential problem.
 `,
-	Flags: append(CommonFlags, numRecordsFlag, fromDatetimeFlag, toDatetimeFlag, averagePeriodFlag, tailFlag, verboseFlag),
+	Flags: append(CommonFlags, showUnknownFlag, numRecordsFlag, fromDatetimeFlag, toDatetimeFlag, averagePeriodFlag, tailFlag, verboseFlag, humanFlag, noHumanFlag, selectorFlag),
 	Action: func(c *cli.Context) {
 		action(c, doAutoscaleHistory)
 	},
 }
 
 func doAutoscale(c *cli.Context) {
-	if len(c.Args()) == 0 {
+	args := serverArgs(c, 0)
+	if len(args) == 0 {
 		displayWrongNumOfArgsAndExit(c)
 	}
-	vename := c.Args().Get(0)
+	vename := args.Get(0)
 
 	resp, err := client.SendRequest("GET", "/ve/"+vename+"/autoscale", nil)
 	assert(err)
@@ -113,21 +115,17 @@ func doAutoscale(c *cli.Context) {
 	autoscale := lib.Autoscale{}
 	assert(xml.Unmarshal(resp.Body, &autoscale))
 
-	outputResult(c, autoscale, func(format string) {
+	outputResponse(c, resp, autoscale, func(format string) {
 		lib.PrintXMLStruct(autoscale)
 	})
 }
 
 func doAutoscaleCreateUpdate(c *cli.Context) {
-	method := "PUT"
-	if c.Command.Name == "autoscale-create" {
-		method = "POST"
-	}
-
-	if len(c.Args()) == 0 {
+	args := serverArgs(c, 0)
+	if len(args) == 0 {
 		displayWrongNumOfArgsAndExit(c)
 	}
-	vename := c.Args().Get(0)
+	vename := args.Get(0)
 
 	var data lib.AutoscaleData
 	if len(c.String("setting-file")) > 0 {
@@ -139,10 +137,8 @@ func doAutoscaleCreateUpdate(c *cli.Context) {
 			displayErrorAndExit("Couldn't find Autoscale rules for '" + vename + "'")
 		}
 	}
-	var b bytes.Buffer
-	assert(xml.NewEncoder(&b).Encode(data))
 
-	resp, err := client.SendRequest(method, "/ve/"+vename+"/autoscale", &b)
+	resp, err := requestAutoscale(vename, c.Command.Name, data)
 	assert(err)
 
 	if resp.StatusCode != 200 {
@@ -157,28 +153,57 @@ func doAutoscaleCreateUpdate(c *cli.Context) {
 	})
 }
 
+// requestAutoscale sends the request which creates the autoscale rules of the
+// server if act is 'autoscale-create' and updates them otherwise
+func requestAutoscale(vename, act string, data lib.AutoscaleData) (*lib.Response, error) {
+	method := "PUT"
+	if act == "autoscale-create" {
+		method = "POST"
+	}
+
+	var b bytes.Buffer
+	if err := xml.NewEncoder(&b).Encode(data); err != nil {
+		return nil, err
+	}
+	return client.SendRequest(method, "/ve/"+vename+"/autoscale", &b)
+}
+
+// sendAutoscale creates or updates the autoscale rules of the server like
+// requestAutoscale and returns the result
+func sendAutoscale(vename, act string, rules []lib.AutoscaleRule) (actionResult, error) {
+	resp, err := requestAutoscale(vename, act, lib.AutoscaleData{AutoscaleRule: rules})
+	if err != nil {
+		return actionResult{}, err
+	}
+	r, err := newActionResult(vename, act, resp, 200)
+	if err == nil {
+		r.Message = "autoscale rules are applied"
+	}
+	return r, err
+}
+
 func doAutoscaleDrop(c *cli.Context) {
-	if len(c.Args()) == 0 {
+	args := serverArgs(c, 0)
+	if len(args) == 0 {
 		displayWrongNumOfArgsAndExit(c)
 	}
-	vename := c.Args().Get(0)
+	vename := args.Get(0)
+	confirmOrExit(c, "drop the autoscale rules of the server", describeVe(c, vename))
 
 	resp, err := client.SendRequest("DELETE", "/ve/"+vename+"/autoscale", nil)
 	assert(err)
 
-	switch resp.StatusCode {
-	case 200:
-		fmt.Println(string(resp.Body))
-	default:
-		displayErrorAndExit(string(resp.Body))
-	}
+	r, err := newActionResult(vename, c.Command.Name, resp, 200)
+	assert(err)
+	outputActionResult(c, r)
 }
 
 func doAutoscaleHistory(c *cli.Context) {
-	if len(c.Args()) == 0 {
+	args := serverArgs(c, 0)
+	if len(args) == 0 {
 		displayWrongNumOfArgsAndExit(c)
 	}
-	vename := c.Args().Get(0)
+	vename := args.Get(0)
 
 	path := "/ve/" + vename + "/autoscale/history/"
 	if len(c.String("from")) > 0 && len(c.String("to")) > 0 {
@@ -215,13 +240,14 @@ func doAutoscaleHistory(c *cli.Context) {
 	assert(xml.Unmarshal(resp.Body, &hst))
 	assert(err)
 
-	outputResult(c, hst, func(format string) {
+	outputResponse(c, resp, hst, func(format string) {
 		if c.Bool("verbose") {
 			lib.PrintXMLStruct(hst)
 		} else {
+			human := humanReadable(c)
 			if len(hst.AutoscaleRule) > 0 {
 				fmt.Println("AUTOSCALE RULE HISTORY")
-				tbl, err := prettytable.NewTable([]prettytable.Column{
+				tbl := newTable(c, []prettytable.Column{
 					{Header: "METRIC"},
 					{Header: "VERSION", AlignRight: true},
 					{Header: "UPDATED"},
@@ -237,11 +263,9 @@ func doAutoscaleHistory(c *cli.Context) {
 					{Header: "DOWN_THRES", AlignRight: true},
 					{Header: "DOWN_PERIOD", AlignRight: true},
 				}...)
-				assert(err)
-				if c.Bool("no-header") {
-					tbl.NoHeader = true
-				}
+				tbl.colorize(4, resultColor)
 				for _, e := range hst.AutoscaleRule {
+					unit := resourceUnit(e.Metric)
 					tbl.AddRow(
 						e.Metric,
 						*e.Version,
@@ -250,9 +274,9 @@ func doAutoscaleHistory(c *cli.Context) {
 						*e.UpdateDeliveredOk,
 						*e.AllowMigration,
 						*e.AllowRestart,
-						e.Limits.Min,
-						e.Limits.Max,
-						e.Limits.Step,
+						formatUnit(e.Limits.Min, unit, human),
+						formatUnit(e.Limits.Max, unit, human),
+						formatUnit(e.Limits.Step, unit, human),
 						*e.Thresholds.Up.Threshold,
 						e.Thresholds.Up.Period,
 						*e.Thresholds.Down.Threshold,
@@ -264,34 +288,30 @@ func doAutoscaleHistory(c *cli.Context) {
 			}
 
 			fmt.Println("RESOURCE CONSUMPTION")
-			tbl, err := prettytable.NewTable([]prettytable.Column{
-				{Header: "CPU_USAGE", AlignRight: true},
-				{Header: "RAM_USAGE", AlignRight: true},
-				{Header: "PRIV_IN", AlignRight: true},
-				{Header: "PRIV_OUT", AlignRight: true},
-				{Header: "PUB_IN", AlignRight: true},
-				{Header: "PUB_OUT", AlignRight: true},
+			tbl := newTable(c, []prettytable.Column{
+				{Header: unitHeader("CPU_USAGE", unitMHz, human), AlignRight: true},
+				{Header: unitHeader("RAM_USAGE", unitMB, human), AlignRight: true},
+				{Header: unitHeader("PRIV_IN", unitBytes, human), AlignRight: true},
+				{Header: unitHeader("PRIV_OUT", unitBytes, human), AlignRight: true},
+				{Header: unitHeader("PUB_IN", unitBytes, human), AlignRight: true},
+				{Header: unitHeader("PUB_OUT", unitBytes, human), AlignRight: true},
 				{Header: "DATETIME"},
-				{Header: "CPU", AlignRight: true},
-				{Header: "RAM", AlignRight: true},
-				{Header: "BANDWIDTH", AlignRight: true},
+				{Header: unitHeader("CPU", unitMHz, human), AlignRight: true},
+				{Header: unitHeader("RAM", unitMB, human), AlignRight: true},
+				{Header: unitHeader("BANDWIDTH", unitKbps, human), AlignRight: true},
 			}...)
-			assert(err)
-			if c.Bool("no-header") {
-				tbl.NoHeader = true
-			}
 			for _, e := range hst.ResourceConsumptionSample {
 				tbl.AddRow(
-					e.CPUUsage,
-					e.RAMUsage,
-					e.PrivateIncomingTraffic,
-					e.PrivateOutgoingTraffic,
-					e.PublicIncomingTraffic,
-					e.PublicOutgoingTraffic,
+					formatUnit(e.CPUUsage, unitMHz, human),
+					formatUnit(e.RAMUsage, unitMB, human),
+					formatTraffic(e.PrivateIncomingTraffic, human),
+					formatTraffic(e.PrivateOutgoingTraffic, human),
+					formatTraffic(e.PublicIncomingTraffic, human),
+					formatTraffic(e.PublicOutgoingTraffic, human),
 					e.PaciTimestamp,
-					e.CPU,
-					e.RAM,
-					e.Bandwidth,
+					formatUnit(e.CPU, unitMHz, human),
+					formatUnit(e.RAM, unitMB, human),
+					formatUnit(e.Bandwidth, unitKbps, human),
 				)
 			}
 			tbl.Print()
@@ -23,8 +23,9 @@ var commandBackupScheduleSet = cli.Command{
 	The <schedule_name> argument must contain the name of a predefined backup
 	schedule. You can obtain the list of the existing schedules using the
 	'backup-schedule' command. You cannot create your own backup schedules.
-`,
-	Flags: CommonFlags,
+
+` + bulkHelp,
+	Flags: append(CommonFlags, allFlag, selectorFlag, parallelFlag),
 	Action: func(c *cli.Context) {
 		action(c, doBackupScheduleSet)
 	},
@@ -36,7 +37,7 @@ var commandBackupScheduleRemove = cli.Command{
 	Description: `
 	This command removes a backup schedule from the specified server.
 `,
-	Flags: CommonFlags,
+	Flags: append(CommonFlags, selectorFlag),
 	Action: func(c *cli.Context) {
 		action(c, doBackupScheduleRemove)
 	},
@@ -47,8 +48,9 @@ var commandBackup = cli.Command{
 	Usage: "Perform an on demand backup of Container/Virtual machine",
 	Description: `
 	This command performs an on-demand backup of the specified server.
-`,
-	Flags: CommonFlags,
+
+` + bulkHelp,
+	Flags: append(CommonFlags, allFlag, selectorFlag, parallelFlag),
 	Action: func(c *cli.Context) {
 		action(c, doBackup)
 	},
@@ -63,7 +65,7 @@ var commandBackupList = cli.Command{
 	--to flags arguments must be used with it to specify datetime interval for
 	which to retrieve the backups.
 `,
-	Flags: append(CommonFlags, fromDatetimeFlag, toDatetimeFlag, verboseFlag),
+	Flags: append(CommonFlags, showUnknownFlag, filterFlag, fromDatetimeFlag, toDatetimeFlag, verboseFlag, humanFlag, noHumanFlag, selectorFlag),
 	Action: func(c *cli.Context) {
 		action(c, doBackupList)
 	},
@@ -81,8 +83,9 @@ var commandBackupRestore = cli.Command{
 	Please note that the complete backup ID string must be specified as the
 	command argument, including curly brackets and any other leading and trailing
 	characters (if any).
-`,
-	Flags: CommonFlags,
+
+` + confirmHelp,
+	Flags: append(CommonFlags, waitFlag, waitTimeoutFlag, pollIntervalFlag, yesFlag, selectorFlag),
 	Action: func(c *cli.Context) {
 		action(c, doBackupRestore)
 	},
@@ -95,7 +98,7 @@ var commandBackupInfo = cli.Command{
 	This command obtains the information about the specified backup. The
 	<backup_id> must contain a valid backup ID. (Please see 'backup-list' command)
 `,
-	Flags: CommonFlags,
+	Flags: append(CommonFlags, showUnknownFlag, selectorFlag),
 	Action: func(c *cli.Context) {
 		action(c, doBackupInfo)
 	},
@@ -110,8 +113,9 @@ var commandBackupDelete = cli.Command{
 
 	The <backup_id> argument must contain a valid backup ID (Please see
 	'backup-list' command)
-`,
-	Flags: CommonFlags,
+
+` + confirmHelp,
+	Flags: append(CommonFlags, yesFlag, selectorFlag),
 	Action: func(c *cli.Context) {
 		action(c, doBackupDelete)
 	},
@@ -127,69 +131,59 @@ var commandBackupSchedule = cli.Command{
 	schedules using this command, then choose a schedule that suits your needs and
 	specify its name when configuring your server.
 `,
-	Flags: append(CommonFlags, noHeaderFlag),
+	Flags: append(CommonFlags, showUnknownFlag, noHeaderFlag),
 	Action: func(c *cli.Context) {
 		action(c, doBackupSchedule)
 	},
 }
 
 func doBackupScheduleSet(c *cli.Context) {
-	if len(c.Args()) < 2 {
+	args := c.Args()
+	if len(args) < 2 && !((c.Bool("all") || len(c.String("selector")) > 0) && len(args) == 1) {
 		displayWrongNumOfArgsAndExit(c)
 	}
-	vename := c.Args().Get(0)
-	schedule := c.Args().Get(1)
+	schedule := args[len(args)-1]
 
-	resp, err := client.SendRequest("PUT", "/ve/"+vename+"/schedule/"+schedule, nil)
-	assert(err)
-
-	switch resp.StatusCode {
-	case 202:
-		fmt.Println(string(resp.Body))
-	default:
-		displayErrorAndExit(string(resp.Body))
-	}
+	runBulk(c, bulkTargets(c, args[:len(args)-1]), func(vename string) (interface{}, error) {
+		resp, err := client.SendRequest("PUT", "/ve/"+vename+"/schedule/"+schedule, nil)
+		if err != nil {
+			return nil, err
+		}
+		return newActionResult(vename, c.Command.Name, resp, 202)
+	}, printActionResult)
 }
 
 func doBackupScheduleRemove(c *cli.Context) {
-	if len(c.Args()) == 0 {
+	args := serverArgs(c, 0)
+	if len(args) == 0 {
 		displayWrongNumOfArgsAndExit(c)
 	}
-	vename := c.Args().Get(0)
+	vename := args.Get(0)
 
 	resp, err := client.SendRequest("PUT", "/ve/"+vename+"/nobackup/", nil)
 	assert(err)
 
-	switch resp.StatusCode {
-	case 202:
-		fmt.Println(string(resp.Body))
-	default:
-		displayErrorAndExit(string(resp.Body))
-	}
+	r, err := newActionResult(vename, c.Command.Name, resp, 202)
+	assert(err)
+	outputActionResult(c, r)
 }
 
 func doBackup(c *cli.Context) {
-	if len(c.Args()) == 0 {
-		displayWrongNumOfArgsAndExit(c)
-	}
-	vename := c.Args().Get(0)
-
-	resp, err := client.SendRequest("POST", "/ve/"+vename+"/backup", nil)
-	assert(err)
-
-	switch resp.StatusCode {
-	case 202:
-		fmt.Println(string(resp.Body))
-	default:
-		displayErrorAndExit(string(resp.Body))
-	}
+	runBulk(c, bulkTargets(c, c.Args()), func(vename string) (interface{}, error) {
+		resp, err := client.SendRequest("POST", "/ve/"+vename+"/backup", nil)
+		if err != nil {
+			return nil, err
+		}
+		return newActionResult(vename, c.Command.Name, resp, 202)
+	}, printActionResult)
 }
 
 func doBackupList(c *cli.Context) {
-	if len(c.Args()) == 0 {
+	args := serverArgs(c, 0)
+	if len(args) == 0 {
 		displayWrongNumOfArgsAndExit(c)
 	}
-	vename := c.Args().Get(0)
+	vename := args.Get(0)
 
 	if len(c.String("from")) == 0 || len(c.String("to")) == 0 {
 		displayErrorAndExit("This command must be used with a pair of --from and --to flags arguments. Please see '" + c.App.Name + " help " + c.Command.Name + "'")
@@ -212,8 +206,9 @@ func doBackupList(c *cli.Context) {
 
 	backups := lib.VeBackups{}
 	assert(xml.Unmarshal(resp.Body, &backups))
+	applyListFilters(c, &backups.Backup)
 
-	outputResult(c, backups, func(format string) {
+	outputResponse(c, resp, backups, func(format string) {
 		if c.Bool("verbose") {
 			lib.PrintXMLStruct(backups)
 		} else {
@@ -222,17 +217,18 @@ func doBackupList(c *cli.Context) {
 			fmt.Printf("  From: %s\n", from.Format(lib.DataTimestampFormat))
 			fmt.Printf("    To: %s\n\n", to.Format(lib.DataTimestampFormat))
 
-			tbl, err := prettytable.NewTable([]prettytable.Column{
+			human := humanReadable(c)
+			tbl := newTable(c, []prettytable.Column{
 				{Header: "ID"},
 				{Header: "SCHEDULE"},
 				{Header: "START"},
 				{Header: "END"},
 				{Header: "RESULT", AlignRight: true},
-				{Header: "SIZE(GB)", AlignRight: true},
+				{Header: unitHeader("SIZE", "GB", human), AlignRight: true},
 				{Header: "NODE"},
 				{Header: "DESCRIPTION"},
 			}...)
-			assert(err)
+			tbl.colorize(4, resultColor)
 			for _, e := range backups.Backup {
 				schedule := "-"
 				if len(e.ScheduleName) > 0 {
@@ -243,6 +239,9 @@ func doBackupList(c *cli.Context) {
 					result = "ok"
 				}
 				size := strconv.FormatFloat(float64(e.BackupSize)/(1<<30), 'f', 3, 64)
+				if human {
+					size = formatByteSize(e.BackupSize)
+				}
 				tbl.AddRow(e.CloudBackupID, schedule, e.Started, e.Ended, result, size, e.BackupNodeName, e.Description)
 			}
 			tbl.Print()
@@ -251,29 +250,31 @@ func doBackupList(c *cli.Context) {
 }
 
 func doBackupRestore(c *cli.Context) {
-	if len(c.Args()) < 2 {
+	args := serverArgs(c, 0)
+	if len(args) < 2 {
 		displayWrongNumOfArgsAndExit(c)
 	}
-	vename := c.Args().Get(0)
-	backupid := getBackupID(c.Args().Get(1))
+	vename := args.Get(0)
+	backupid := getBackupID(args.Get(1))
+	confirmOrExit(c, "restore the server from backup "+backupid+" and overwrite its data", describeBackup(c, vename, backupid))
 
 	resp, err := client.SendRequest("PUT", "/ve/"+vename+"/restore/"+backupid, nil)
 	assert(err)
 
-	switch resp.StatusCode {
-	case 202:
-		fmt.Println(string(resp.Body))
-	default:
-		displayErrorAndExit(string(resp.Body))
-	}
+	r, err := newActionResult(vename+"/"+backupid, c.Command.Name, resp, 202)
+	assert(err)
+	outputActionResult(c, r)
+
+	waitIfRequested(c, vename, waitSteady)
 }
 
 func doBackupInfo(c *cli.Context) {
-	if len(c.Args()) < 2 {
+	args := serverArgs(c, 0)
+	if len(args) < 2 {
 		displayWrongNumOfArgsAndExit(c)
 	}
-	vename := c.Args().Get(0)
-	backupid := getBackupID(c.Args().Get(1))
+	vename := args.Get(0)
+	backupid := getBackupID(args.Get(1))
 
 	resp, err := client.SendRequest("GET", "/ve/"+vename+"/backup/"+backupid, nil)
 	assert(err)
@@ -284,27 +285,26 @@ func doBackupInfo(c *cli.Context) {
 	backup := lib.Backup{}
 	assert(xml.Unmarshal(resp.Body, &backup))
 
-	outputResult(c, backup, func(format string) {
+	outputResponse(c, resp, backup, func(format string) {
 		lib.PrintXMLStruct(backup)
 	})
 }
 
 func doBackupDelete(c *cli.Context) {
-	if len(c.Args()) < 2 {
+	args := serverArgs(c, 0)
+	if len(args) < 2 {
 		displayWrongNumOfArgsAndExit(c)
 	}
-	vename := c.Args().Get(0)
-	backupid := getBackupID(c.Args().Get(1))
+	vename := args.Get(0)
+	backupid := getBackupID(args.Get(1))
+	confirmOrExit(c, "delete backup "+backupid+" of the server", describeBackup(c, vename, backupid))
 
 	resp, err := client.SendRequest("DELETE", "/ve/"+vename+"/backup/"+backupid, nil)
 	assert(err)
 
-	switch resp.StatusCode {
-	case 202:
-		fmt.Println(string(resp.Body))
-	default:
-		displayErrorAndExit(string(resp.Body))
-	}
+	r, err := newActionResult(vename+"/"+backupid, c.Command.Name, resp, 202)
+	assert(err)
+	outputActionResult(c, r)
 }
 
 func doBackupSchedule(c *cli.Context) {
@@ -317,8 +317,8 @@ func doBackupSchedule(c *cli.Context) {
 	backups := lib.BackupScheduleList{}
 	assert(xml.Unmarshal(resp.Body, &backups))
 
-	outputResult(c, backups, func(format string) {
-		tbl, err := prettytable.NewTable([]prettytable.Column{
+	outputResponse(c, resp, backups, func(format string) {
+		tbl := newTable(c, []prettytable.Column{
 			{Header: "ID", AlignRight: true},
 			{Header: "NAME"},
 			{Header: "DESCRIPTION"},
@@ -326,10 +326,6 @@ func doBackupSchedule(c *cli.Context) {
 			{Header: "KEEP", AlignRight: true},
 			{Header: "INCREMENTAL", AlignRight: true},
 		}...)
-		assert(err)
-		if c.Bool("no-header") {
-			tbl.NoHeader = true
-		}
 		for _, e := range backups.BackupSchedule {
 			tbl.AddRow(e.ID, e.Name, e.Description, e.Enabled, e.BackupsToKeep, e.NoOfIncremental)
 		}
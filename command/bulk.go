@@ -0,0 +1,384 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/codegangsta/cli"
+	"github.com/tatsushid/go-prettytable"
+	"github.com/tsukaeru/pacicli/lib"
+)
+
+// bulkResult is a result of an operation on one of the target servers of
+// a bulk operation
+type bulkResult struct {
+	Server  string
+	Success bool
+	Error   string      `json:",omitempty" toml:",omitempty"`
+	Result  interface{} `json:",omitempty" toml:",omitempty"`
+}
+
+// bulkHelp is the paragraph of the descriptions of the commands which accept
+// multiple servers
+const bulkHelp = `	Multiple server names and glob patterns like 'web-*' can be specified, or
+	--all option selects all servers. --selector option like '-l role=web'
+	selects the servers by their labels. The servers are processed in parallel
+	and a summary of the results is printed.
+`
+
+// bulkOperation is an operation done on each target server. It returns
+// a value to be output as the result
+type bulkOperation func(vename string) (interface{}, error)
+
+// resolveTargets returns the names of the servers which an operation is done
+// on. A name which contains glob meta characters is matched with the names of
+// the existing servers and --all option selects all servers. --selector option
+// selects the servers by their labels from the names, or from all servers if
+// no name is specified
+func resolveTargets(c *cli.Context, names []string) ([]string, error) {
+	return resolveServers(names, c.Bool("all"), c.String("selector"))
+}
+
+// resolveServers resolves the server names, glob patterns, all and selector
+// as resolveTargets does for the options
+func resolveServers(names []string, all bool, selector string) ([]string, error) {
+	var velist *lib.VeList
+	allVe := func() (*lib.VeList, error) {
+		if velist == nil {
+			l := lib.VeList{}
+			if err := getResource("/ve", &l); err != nil {
+				return nil, err
+			}
+			velist = &l
+		}
+		return velist, nil
+	}
+
+	var targets []string
+	seen := make(map[string]bool)
+	add := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			targets = append(targets, name)
+		}
+	}
+
+	if all || len(selector) > 0 && len(names) == 0 {
+		l, err := allVe()
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range l.VeInfo {
+			add(e.Name)
+		}
+	}
+	for _, name := range names {
+		if !strings.ContainsAny(name, "*?[") {
+			add(name)
+			continue
+		}
+		l, err := allVe()
+		if err != nil {
+			return nil, err
+		}
+		matched := false
+		for _, e := range l.VeInfo {
+			ok, err := path.Match(name, e.Name)
+			if err != nil {
+				return nil, errors.New("Invalid server name pattern '" + name + "'")
+			}
+			if ok {
+				matched = true
+				add(e.Name)
+			}
+		}
+		if !matched {
+			return nil, errors.New("There is no server matching '" + name + "'")
+		}
+	}
+
+	if len(selector) == 0 {
+		return targets, nil
+	}
+	targets, err := selectByLabels(selector, targets)
+	if err != nil {
+		return nil, err
+	}
+	if len(targets) == 0 {
+		return nil, errors.New("There is no server matching the selector '" + selector + "'")
+	}
+	return targets, nil
+}
+
+// bulkTargets resolves the target servers from names and exits if there is
+// no target
+func bulkTargets(c *cli.Context, names []string) []string {
+	targets, err := resolveTargets(c, names)
+	assert(err)
+	if len(targets) == 0 {
+		if c.Bool("all") {
+			displayErrorAndExit("There are no servers")
+		}
+		displayWrongNumOfArgsAndExit(c)
+	}
+	return targets
+}
+
+// isBulkRequest reports whether the target servers are selected by a glob
+// pattern, --all or --selector. The results of such a request are output as
+// a summary even for one server so that the output doesn't change its shape
+// with the number of the matched servers
+func isBulkRequest(c *cli.Context) bool {
+	if c.Bool("all") || len(c.String("selector")) > 0 {
+		return true
+	}
+	for _, arg := range c.Args() {
+		if strings.ContainsAny(arg, "*?[") {
+			return true
+		}
+	}
+	return false
+}
+
+// runBulk does op on every target server. A single target which is named
+// explicitly is processed and output as the command did for one server.
+// Otherwise the targets are processed in parallel and a summary of the results
+// is output. The command exits with non-zero code if the operation failed on
+// any server and with exitCodeTimeout if it only timed out waiting for them
+func runBulk(c *cli.Context, targets []string, op bulkOperation, printFn func(v interface{})) {
+	runBulkWait(c, targets, "", op, printFn)
+}
+
+// runBulkWait works like runBulk but it also waits for each server to satisfy
+// the condition want after op succeeds when --wait option is specified. The
+// result of op is kept even if the wait fails, and if op fails with
+// a *passwordSaveError not to lose the password
+func runBulkWait(c *cli.Context, targets []string, want string, op bulkOperation, printFn func(v interface{})) {
+	checkPasswordFile(c, len(targets))
+	if len(targets) == 1 && !isBulkRequest(c) {
+		v, err := op(targets[0])
+		if _, ok := err.(*passwordSaveError); !ok {
+			assertWait(err)
+		}
+		outputResult(c, v, func(format string) {
+			printFn(v)
+		})
+		assert(err)
+		if len(want) > 0 {
+			waitIfRequested(c, targets[0], want)
+		}
+		return
+	}
+
+	vs, errs := runSteps(c, len(targets), func(i int) (interface{}, error) {
+		return op(targets[i])
+	}, func(i int) error {
+		if len(want) == 0 {
+			return nil
+		}
+		return requestedWait(c, targets[i], want)
+	})
+	results := make([]bulkResult, len(targets))
+	timedOut := make([]bool, len(targets))
+	for i, err := range errs {
+		results[i] = bulkResult{Server: targets[i], Success: err == nil, Result: vs[i]}
+		if err != nil {
+			results[i].Error = strings.TrimSpace(err.Error())
+		}
+		_, timedOut[i] = err.(*waitTimeoutError)
+	}
+	if c.Bool("dry-run") {
+		for _, r := range results {
+			if !r.Success {
+				displayErrorAndExit(r.Server + ": " + r.Error)
+			}
+		}
+		return
+	}
+
+	outputResult(c, results, func(format string) {
+		printSummary(c, []string{"SERVER"}, len(results), func(i int) ([]interface{}, bool, interface{}, string) {
+			r := results[i]
+			return []interface{}{r.Server}, r.Success, r.Result, r.Error
+		})
+	})
+
+	code := 0
+	for i, r := range results {
+		switch {
+		case r.Success:
+		case timedOut[i]:
+			if code == 0 {
+				code = exitCodeTimeout
+			}
+		default:
+			code = exitCodeError
+		}
+	}
+	if code != 0 {
+		os.Exit(code)
+	}
+}
+
+// runSteps calls op with every index from 0 to n-1 in parallel up to
+// --parallel and then wait if op succeeds. The requests are sent one by one on
+// dry run so that they are printed in order. The value of op is dropped if it
+// fails unless the error is a *passwordSaveError, because the value has the
+// password which couldn't be saved. lib.ErrDryRun isn't returned as an error
+func runSteps(c *cli.Context, n int, op func(i int) (interface{}, error), wait func(i int) error) ([]interface{}, []error) {
+	workers := c.Int("parallel")
+	if c.Bool("dry-run") {
+		workers = 1
+	}
+	vs := make([]interface{}, n)
+	errs := make([]error, n)
+	runParallel(n, workers, func(i int) {
+		v, err := op(i)
+		if _, ok := err.(*passwordSaveError); ok {
+			// v has the password which couldn't be saved
+		} else if err != nil {
+			v = nil
+		} else {
+			err = wait(i)
+		}
+		if err == lib.ErrDryRun {
+			err = nil
+		}
+		vs[i], errs[i] = v, err
+	})
+	return vs, errs
+}
+
+// printSummary prints a table of the results of operations. The columns are
+// headers followed by RESULT and MESSAGE. row returns the cells of headers,
+// whether the operation succeeded, its value and error message of the i-th
+// result. The value of a failed operation is shown with the error
+func printSummary(c *cli.Context, headers []string, n int, row func(i int) ([]interface{}, bool, interface{}, string)) {
+	var cols []prettytable.Column
+	for _, h := range headers {
+		cols = append(cols, prettytable.Column{Header: h})
+	}
+	cols = append(cols, prettytable.Column{Header: "RESULT"}, prettytable.Column{Header: "MESSAGE"})
+	tbl := newTable(c, cols...)
+	tbl.colorize(len(headers), resultColor)
+	for i := 0; i < n; i++ {
+		cells, ok, v, msg := row(i)
+		switch {
+		case ok:
+			cells = append(cells, "ok", bulkMessage(v))
+		case v != nil:
+			cells = append(cells, "fail", bulkMessage(v)+" ("+msg+")")
+		default:
+			cells = append(cells, "fail", msg)
+		}
+		tbl.AddRow(cells...)
+	}
+	tbl.Print()
+}
+
+// maxPatternNames is the maximum number of the names which a name pattern can
+// be expanded to. It prevents a typo like '{1..1000}' from creating servers
+// more than intended
+const maxPatternNames = 100
+
+// expandNamePattern expands the brace patterns in name like a shell does.
+// '{01..10}' is expanded to the numbers from 01 to 10 keeping the width of the
+// zero padded bounds and '{a,b}' is expanded to each of the words. It fails if
+// the pattern is expanded to more than maxPatternNames names
+func expandNamePattern(name string) ([]string, error) {
+	i := strings.Index(name, "{")
+	if i < 0 {
+		return []string{name}, nil
+	}
+	j := strings.Index(name[i:], "}")
+	if j < 0 {
+		return nil, errors.New("Unclosed '{' in name pattern '" + name + "'")
+	}
+	j += i
+
+	words, err := expandBrace(name[i+1 : j])
+	if err != nil {
+		return nil, err
+	}
+	rests, err := expandNamePattern(name[j+1:])
+	if err != nil {
+		return nil, err
+	}
+
+	if len(words)*len(rests) > maxPatternNames {
+		return nil, fmt.Errorf("Name pattern '%s' is expanded to more than %d names", name, maxPatternNames)
+	}
+	var names []string
+	for _, w := range words {
+		for _, r := range rests {
+			names = append(names, name[:i]+w+r)
+		}
+	}
+	return names, nil
+}
+
+func expandBrace(s string) ([]string, error) {
+	if !strings.Contains(s, "..") {
+		return strings.Split(s, ","), nil
+	}
+
+	bounds := strings.SplitN(s, "..", 2)
+	from, err := strconv.Atoi(bounds[0])
+	if err != nil {
+		return nil, errors.New("Invalid range '{" + s + "}' in name pattern")
+	}
+	to, err := strconv.Atoi(bounds[1])
+	if err != nil {
+		return nil, errors.New("Invalid range '{" + s + "}' in name pattern")
+	}
+
+	width := 0
+	for _, b := range bounds {
+		if len(b) > 1 && b[0] == '0' && len(b) > width {
+			width = len(b)
+		}
+	}
+	step := 1
+	if from > to {
+		step = -1
+	}
+	if (to-from)*step >= maxPatternNames {
+		return nil, fmt.Errorf("Range '{%s}' in name pattern has more than %d numbers", s, maxPatternNames)
+	}
+	var words []string
+	for n := from; ; n += step {
+		words = append(words, fmt.Sprintf("%0*d", width, n))
+		if n == to {
+			break
+		}
+	}
+	return words, nil
+}
+
+// instanceHostname derives the host name of an instance of a batch creation
+// from the host name in the spec. The domain part of it is kept
+func instanceHostname(hostname, name string) string {
+	if i := strings.Index(hostname, "."); i >= 0 {
+		return name + hostname[i:]
+	}
+	return name
+}
+
+// bulkMessage returns a short message of an operation result for a summary
+// table
+func bulkMessage(v interface{}) string {
+	switch r := v.(type) {
+	case actionResult:
+		return r.Message
+	case lib.PasswordResponse:
+		if len(r.Password) == 0 {
+			return r.Message
+		}
+		return "password: " + r.Password
+	}
+	return ""
+}
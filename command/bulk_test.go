@@ -0,0 +1,87 @@
+package command
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExpandNamePattern(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		want []string
+	}{
+		{"web1", []string{"web1"}},
+		{"web-{1..3}", []string{"web-1", "web-2", "web-3"}},
+		{"web-{08..10}", []string{"web-08", "web-09", "web-10"}},
+		{"web-{3..1}", []string{"web-3", "web-2", "web-1"}},
+		{"web-{1..01}", []string{"web-01"}},
+		{"{a,b}-{1..2}", []string{"a-1", "a-2", "b-1", "b-2"}},
+		{"db-{master,slave}.local", []string{"db-master.local", "db-slave.local"}},
+		{"web-{7}", []string{"web-7"}},
+	} {
+		got, err := expandNamePattern(tt.name)
+		if err != nil {
+			t.Errorf("expandNamePattern(%q) returned an error: %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("expandNamePattern(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestExpandNamePatternInvalid(t *testing.T) {
+	for _, name := range []string{
+		"web-{1..3",
+		"web-{a..3}",
+		"web-{1..b}",
+		"web-{1..1000}",
+		"web-{0..100}",
+		"{1..20}-{1..20}",
+	} {
+		if _, err := expandNamePattern(name); err == nil {
+			t.Errorf("expandNamePattern(%q) didn't return an error", name)
+		}
+	}
+}
+
+func TestExpandNamePatternLimit(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		want int
+	}{
+		{"web-{1..100}", maxPatternNames},
+		{"{a,b}-{1..50}", maxPatternNames},
+	} {
+		names, err := expandNamePattern(tt.name)
+		if err != nil {
+			t.Errorf("expandNamePattern(%q) returned an error: %v", tt.name, err)
+			continue
+		}
+		if len(names) != tt.want {
+			t.Errorf("expandNamePattern(%q) returned %d names, want %d", tt.name, len(names), tt.want)
+		}
+	}
+}
+
+func TestExpandBrace(t *testing.T) {
+	for _, tt := range []struct {
+		s    string
+		want []string
+	}{
+		{"a,b,c", []string{"a", "b", "c"}},
+		{"1..3", []string{"1", "2", "3"}},
+		{"-1..1", []string{"-1", "0", "1"}},
+		{"09..11", []string{"09", "10", "11"}},
+		{"5..5", []string{"5"}},
+	} {
+		got, err := expandBrace(tt.s)
+		if err != nil {
+			t.Errorf("expandBrace(%q) returned an error: %v", tt.s, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("expandBrace(%q) = %q, want %q", tt.s, got, tt.want)
+		}
+	}
+}
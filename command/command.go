@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
 
 	"github.com/BurntSushi/toml"
@@ -21,6 +23,7 @@ var Commands = []cli.Command{
 	commandList,
 	commandStart,
 	commandStop,
+	commandRestart,
 	commandCreate,
 	commandCreateFromImage,
 	commandClone,
@@ -67,47 +70,68 @@ var Commands = []cli.Command{
 	commandOSList,
 	commandBackupSchedule,
 	commandInitiatingVnc,
+	commandSSH,
+	commandDiff,
+	commandExport,
+	commandWaitFor,
+	commandStatus,
+	commandScheduler,
+	commandPlan,
+	commandApply,
+	commandDrift,
+	commandDestroy,
 }
 
 var commandSynopsisses = map[string]string{
 	"list":                   "[options]",
-	"start":                  "<server_name> [options]",
-	"stop":                   "<server_name> [options]",
+	"start":                  "{<server_name ...> | --all | -l <selector>} [options]",
+	"stop":                   "{<server_name ...> | --all | -l <selector>} [options]",
+	"restart":                "{<server_name ...> | --all | -l <selector>} [options]",
 	"create":                 "<server_name> [options]",
 	"create-from-image":      "<server_name> <image_name> [options]",
 	"clone":                  "<src_server_name> <dst_server_name> [options]",
-	"recreate":               "<server_name> [options]",
-	"modify":                 "<server_name> [options]",
-	"reset-password":         "<server_name> [options]",
-	"info":                   "<server_name> [options]",
-	"history":                "<server_name> {-f <from> -t <to> | -n <num>} [options]",
-	"usage":                  "<server_name> -f <from> -t <to> [options]",
-	"delete":                 "<server_name> [options]",
-	"vnc":                    "<server_name> [options]",
-	"fwlist":                 "<server_name> [options]",
-	"fwcreate":               "<server_name> [options]",
-	"fwmodify":               "<server_name> [options]",
-	"fwdelete":               "<server_name> [options]",
-	"backup-schedule-set":    "<server_name> <schedule_name> [options]",
-	"backup-schedule-remove": "<server_name> [options]",
-	"backup":                 "<server_name> [options]",
-	"backup-list":            "<server_name> -f <from> -t <to> [options]",
-	"backup-restore":         "<server_name> <backup_id> [options]",
-	"backup-info":            "<server_name> <backup_id> [options]",
-	"backup-delete":          "<server_name> <backup_id> [options]",
-	"autoscale":              "<server_name> [options]",
-	"autoscale-create":       "<server_name> [options]",
-	"autoscale-update":       "<server_name> [options]",
-	"autoscale-drop":         "<server_name> [options]",
-	"autoscale-history":      "<server_name> {-f <from> -t <to> | -n <num>} [options]",
+	"recreate":               "{<server_name> | -l <selector>} [options]",
+	"modify":                 "{<server_name> | -l <selector>} [options]",
+	"reset-password":         "{<server_name ...> | --all | -l <selector>} [options]",
+	"info":                   "{<server_name> | -l <selector>} [options]",
+	"history":                "{<server_name> | -l <selector>} {-f <from> -t <to> | -n <num>} [options]",
+	"usage":                  "{<server_name> | -l <selector>} -f <from> -t <to> [options]",
+	"delete":                 "{<server_name ...> | --all | -l <selector>} [options]",
+	"vnc":                    "{<server_name> | -l <selector>} [options]",
+	"ssh":                    "{<server_name> | -l <selector>} [options] [-- <command ...>]",
+	"diff":                   "{{<server_name> | -l <selector>} <server_name> | {<server_name> | -l <selector>} --against-spec} [options]",
+	"export":                 "{<server_name ...> | --all | -l <selector>} [options]",
+	"wait-for":               "{{<server_name> | -l <selector>} --state <state> | --lb <lb_name> | {<server_name> | -l <selector>} --app <app_name> [--app-state <state>]} [options]",
+	"status":                 "[options]",
+	"scheduler":              "{run | plan} [options]",
+	"plan":                   "[<server_name ...>] [options]",
+	"apply":                  "[<server_name ...>] [options]",
+	"drift":                  "[<server_name ...>] [options]",
+	"destroy":                "[--target <name> ...] [options]",
+	"fwlist":                 "{<server_name> | -l <selector>} [options]",
+	"fwcreate":               "{<server_name> | -l <selector>} [options]",
+	"fwmodify":               "{<server_name ...> | --all | -l <selector>} [options]",
+	"fwdelete":               "{<server_name> | -l <selector>} [options]",
+	"backup-schedule-set":    "{<server_name ...> | --all | -l <selector>} <schedule_name> [options]",
+	"backup-schedule-remove": "{<server_name> | -l <selector>} [options]",
+	"backup":                 "{<server_name ...> | --all | -l <selector>} [options]",
+	"backup-list":            "{<server_name> | -l <selector>} -f <from> -t <to> [options]",
+	"backup-restore":         "{<server_name> | -l <selector>} <backup_id> [options]",
+	"backup-info":            "{<server_name> | -l <selector>} <backup_id> [options]",
+	"backup-delete":          "{<server_name> | -l <selector>} <backup_id> [options]",
+	"autoscale":              "{<server_name> | -l <selector>} [options]",
+	"autoscale-create":       "{<server_name> | -l <selector>} [options]",
+	"autoscale-update":       "{<server_name> | -l <selector>} [options]",
+	"autoscale-drop":         "{<server_name> | -l <selector>} [options]",
+	"autoscale-history":      "{<server_name> | -l <selector>} {-f <from> -t <to> | -n <num>} [options]",
 	"applist":                "[options]",
 	"appinfo":                "<app_name> <os_name> [options]",
-	"appinstall":             "<server_name> <app_name ...> [options]",
-	"appreset":               "<server_name> <app_name ...> [options]",
-	"appdelete":              "<server_name> <app_name> [options]",
+	"appinstall":             "{<server_name> | -l <selector>} <app_name ...> [options]",
+	"appreset":               "{<server_name> | -l <selector>} <app_name ...> [options]",
+	"appdelete":              "{<server_name> | -l <selector>} <app_name> [options]",
 	"imglist":                "[options]",
 	"imginfo":                "<image_name> [options]",
-	"imgcreate":              "<server_name> <image_name> [options]",
+	"imgcreate":              "{<server_name> | -l <selector>} <image_name> [options]",
 	"imgdelete":              "<image_name> [options]",
 	"lblist":                 "[options]",
 	"lbinfo":                 "<lb_name> [options]",
@@ -115,8 +139,8 @@ var commandSynopsisses = map[string]string{
 	"lbcreate":               "<lb_name> [options]",
 	"lbrestart":              "<lb_name> [options]",
 	"lbdelete":               "<lb_name> [options]",
-	"lbattach":               "<lb_name> <server_name> [options]",
-	"lbdetach":               "<lb_name> <server_name> [options]",
+	"lbattach":               "<lb_name> {<server_name> | -l <selector>} [options]",
+	"lbdetach":               "<lb_name> {<server_name> | -l <selector>} [options]",
 	"oslist":                 "[<os_name>] [options]",
 	"backup-schedule":        "[options]",
 }
@@ -128,6 +152,9 @@ const (
 )
 
 func assert(err error, v ...interface{}) {
+	if err == lib.ErrDryRun {
+		os.Exit(0)
+	}
 	if err != nil {
 		a := []interface{}{err}
 		if len(v) > 0 {
@@ -138,9 +165,21 @@ func assert(err error, v ...interface{}) {
 	}
 }
 
+// Exit codes of the command. exitCodeTimeout is the same as timeout(1) uses
+// and exitCodeDrift is used by 'drift' command like diff(1) uses 1
+const (
+	exitCodeError   = 1
+	exitCodeDrift   = 2
+	exitCodeTimeout = 124
+)
+
 func displayErrorAndExit(a ...interface{}) {
+	exitWithError(exitCodeError, a...)
+}
+
+func exitWithError(code int, a ...interface{}) {
 	fmt.Fprintln(os.Stderr, a...)
-	os.Exit(1)
+	os.Exit(code)
 }
 
 func displayWrongNumOfArgsAndExit(c *cli.Context) {
@@ -168,6 +207,10 @@ func outputResult(c *cli.Context, v interface{}, defaultFn func(format string))
 		}
 		fmt.Println(string(b))
 	case "toml":
+		// TOML document can't have an array at the top level
+		if reflect.ValueOf(v).Kind() == reflect.Slice {
+			v = map[string]interface{}{"Items": v}
+		}
 		return toml.NewEncoder(os.Stdout).Encode(v)
 	default:
 		defaultFn(f)
@@ -175,6 +218,31 @@ func outputResult(c *cli.Context, v interface{}, defaultFn func(format string))
 	return nil
 }
 
+// outputResponse works like outputResult but it also supports the output and
+// the debug options which need a raw response body. "xml" output format prints
+// the pretty printed response body as it is and --show-unknown option reports
+// XML elements and attributes which v doesn't have any field for
+func outputResponse(c *cli.Context, resp *lib.Response, v interface{}, defaultFn func(format string)) error {
+	if c.Bool("show-unknown") {
+		fields, err := lib.UnknownXMLFields(resp.Body, v)
+		if err != nil {
+			return err
+		}
+		for _, e := range fields {
+			fmt.Fprintln(os.Stderr, "Unknown XML field:", e)
+		}
+	}
+	if strings.ToLower(c.String("output")) == "xml" {
+		b, err := lib.IndentXML(resp.Body)
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(b))
+		return nil
+	}
+	return outputResult(c, v, defaultFn)
+}
+
 var (
 	conf   lib.Config
 	client *lib.Client
@@ -184,11 +252,19 @@ func action(c *cli.Context, fn func(c *cli.Context)) {
 	if len(c.String("config")) > 0 {
 		err := lib.LoadConfig(c.String("config"), &conf)
 		assert(err)
+		if len(conf.LabelsFile) > 0 && !filepath.IsAbs(conf.LabelsFile) {
+			conf.LabelsFile = filepath.Join(filepath.Dir(c.String("config")), conf.LabelsFile)
+		}
 		if len(conf.BaseURL) == 0 || len(conf.Username) == 0 || len(conf.Password) == 0 {
 			displayErrorAndExit("Invalid config data. BaseURL, Username and Password must be correctly specified in a config file")
 		}
 		client = lib.NewClient(conf.BaseURL, conf.Username, conf.Password)
+		if c.Bool("dry-run") {
+			client.DryRun = printDryRun(c)
+		}
 		prettytable.Separator = columnSeparator
+		assertWaitOptions(c)
+		assertPasswordOptions(c)
 		fn(c)
 	} else {
 		displayErrorAndExit("Config path is empty. It must be specified to use this command.\nPlease see '" + c.App.Name + " help' result")
@@ -0,0 +1,204 @@
+package command
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/codegangsta/cli"
+	"github.com/tsukaeru/pacicli/lib"
+)
+
+// confirmHelp is the paragraph of the descriptions of the commands which ask
+// for confirmation
+const confirmHelp = `	It asks for confirmation showing the details of the target unless --yes
+	option is specified. Without --yes, it refuses to run when stdin isn't
+	a terminal.
+`
+
+// confirmTarget is a resource which a destructive command is going to change.
+// Its details are shown to the user before asking for confirmation
+type confirmTarget struct {
+	kind       string
+	name       string
+	details    []string
+	production bool
+}
+
+// veTarget builds confirmTarget of a server with its state, IP addresses and
+// attached load balancer. A server marked with Production in Pacifile requires
+// its name to be typed to confirm
+func veTarget(vename string) confirmTarget {
+	t := confirmTarget{kind: "server", name: vename, production: isProduction(vename)}
+	ve, err := getVe(vename)
+	if err != nil {
+		t.details = append(t.details, "details: "+strings.TrimSpace(err.Error()))
+		return t
+	}
+	t.details = append(t.details, "state: "+ve.State)
+	for _, e := range ve.Network.PublicIP {
+		t.details = append(t.details, "ipv4: "+e.Address.IP.String())
+	}
+	for _, e := range ve.Network.PublicIP6 {
+		t.details = append(t.details, "ipv6: "+e.Address.IP.String())
+	}
+	if len(ve.LoadBalancer) > 0 {
+		t.details = append(t.details, "load balancer: "+ve.LoadBalancer)
+	}
+	return t
+}
+
+// lbTarget builds confirmTarget of a load balancer with its state, IP addresses
+// and member servers
+func lbTarget(lbname string) confirmTarget {
+	t := confirmTarget{kind: "load balancer", name: lbname}
+	lb := lib.LoadBalancer{}
+	if err := getResource("/load-balancer/"+lbname, &lb); err != nil {
+		t.details = append(t.details, "details: "+strings.TrimSpace(err.Error()))
+		return t
+	}
+	t.details = append(t.details, "state: "+lb.State)
+	for _, e := range lb.Network.PublicIP {
+		t.details = append(t.details, "ipv4: "+e.Address.IP.String())
+	}
+	for _, e := range lb.UsedBy {
+		t.details = append(t.details, "member: "+e.VeName)
+	}
+	return t
+}
+
+// imageTarget builds confirmTarget of an image with the server it was made of
+func imageTarget(imgname string) confirmTarget {
+	t := confirmTarget{kind: "image", name: imgname}
+	img := lib.VeImage{}
+	if err := getResource("/image/"+imgname, &img); err != nil {
+		t.details = append(t.details, "details: "+strings.TrimSpace(err.Error()))
+		return t
+	}
+	t.details = append(t.details,
+		"image of: "+img.ImageOf,
+		"created: "+img.Created.String(),
+		"size: "+formatByteSize(img.ImageSize),
+	)
+	return t
+}
+
+// backupTarget builds confirmTarget of a backup with its time, size and
+// schedule
+func backupTarget(vename, backupid string) confirmTarget {
+	t := confirmTarget{kind: "backup", name: backupid}
+	b := lib.Backup{}
+	if err := getResource("/ve/"+vename+"/backup/"+backupid, &b); err != nil {
+		t.details = append(t.details, "details: "+strings.TrimSpace(err.Error()))
+		return t
+	}
+	schedule := "-"
+	if len(b.ScheduleName) > 0 {
+		schedule = b.ScheduleName
+	}
+	t.details = append(t.details,
+		"server: "+vename,
+		"started: "+b.Started.String(),
+		"size: "+formatByteSize(b.BackupSize),
+		"schedule: "+schedule,
+	)
+	return t
+}
+
+// describeVe returns a function which builds confirmTarget of the servers.
+// The details are fetched in parallel only when the confirmation is needed
+func describeVe(c *cli.Context, names ...string) func() []confirmTarget {
+	return func() []confirmTarget {
+		targets := make([]confirmTarget, len(names))
+		runParallel(len(names), c.Int("parallel"), func(i int) {
+			targets[i] = veTarget(names[i])
+		})
+		return targets
+	}
+}
+
+// describeBackup returns a function which builds confirmTarget of the backup
+// and the server it belongs to
+func describeBackup(c *cli.Context, vename, backupid string) func() []confirmTarget {
+	return func() []confirmTarget {
+		return append([]confirmTarget{backupTarget(vename, backupid)}, veTarget(vename))
+	}
+}
+
+func describeLb(lbname string) func() []confirmTarget {
+	return func() []confirmTarget {
+		return []confirmTarget{lbTarget(lbname)}
+	}
+}
+
+func describeImage(imgname string) func() []confirmTarget {
+	return func() []confirmTarget {
+		return []confirmTarget{imageTarget(imgname)}
+	}
+}
+
+func isProduction(vename string) bool {
+	s, ok := conf.Servers[vename]
+	return ok && s.Production
+}
+
+// confirmOrExit shows the targets which describe returns and asks the user
+// whether what should be done. The command exits if the user doesn't agree.
+// --yes option skips it and it refuses to go on without --yes when stdin isn't
+// a terminal
+func confirmOrExit(c *cli.Context, what string, describe func() []confirmTarget) {
+	if c.Bool("yes") || c.Bool("dry-run") {
+		return
+	}
+	if !isTerminal(os.Stdin) {
+		displayErrorAndExit("Refusing to " + what + " without confirmation because stdin is not a terminal. Use --yes option to skip the confirmation")
+	}
+
+	targets := describe()
+	fmt.Fprintln(os.Stderr, "The command is going to "+what+":")
+	for _, t := range targets {
+		fmt.Fprintln(os.Stderr)
+		mark := ""
+		if t.production {
+			mark = " (production)"
+		}
+		fmt.Fprintf(os.Stderr, "  %s %s%s\n", t.kind, t.name, mark)
+		for _, d := range t.details {
+			fmt.Fprintln(os.Stderr, "    "+d)
+		}
+	}
+	fmt.Fprintln(os.Stderr)
+
+	r := bufio.NewReader(os.Stdin)
+	production := false
+	for _, t := range targets {
+		if !t.production {
+			continue
+		}
+		production = true
+		s := prompt(r, "'"+t.name+"' is marked as production. Type its name to confirm: ")
+		if s != t.name {
+			displayErrorAndExit("The name didn't match. Aborted")
+		}
+	}
+	if production {
+		return
+	}
+
+	switch strings.ToLower(prompt(r, "Do you want to continue? [y/N]: ")) {
+	case "y", "yes":
+	default:
+		displayErrorAndExit("Aborted")
+	}
+}
+
+func prompt(r *bufio.Reader, msg string) string {
+	fmt.Fprint(os.Stderr, msg)
+	s, err := r.ReadString('\n')
+	if err != nil && err != io.EOF {
+		assert(err)
+	}
+	return strings.TrimSpace(s)
+}
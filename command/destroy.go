@@ -0,0 +1,247 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/codegangsta/cli"
+	"github.com/tsukaeru/pacicli/lib"
+)
+
+var commandDestroy = cli.Command{
+	Name:  "destroy",
+	Usage: "Delete all resources declared in Pacifile",
+	Description: `
+	This command deletes every server declared in '[Servers.<name>]' sections
+	of Pacifile and the load balancers and images listed in 'LoadBalancers' and
+	'Images'. It's intended to tear down an ephemeral environment.
+
+	The resources are processed in the order below. Each step is done in
+	parallel up to --parallel and a server which failed in a step is skipped in
+	the following steps.
+
+	  1. detach the servers from their load balancers and the members of the
+	     load balancers, waiting for the servers to be steady
+	  2. stop the servers and wait for them to be stopped
+	  3. delete the servers and wait for them to be deleted
+	  4. delete the load balancers and the images
+
+	--target option restricts the resources to the named ones. Resources which
+	don't exist are skipped. It asks for confirmation showing all targets unless
+	--yes option is specified.
+`,
+	Flags: append(CommonFlags, targetFlag, yesFlag, parallelFlag, waitTimeoutFlag, pollIntervalFlag),
+	Action: func(c *cli.Context) {
+		action(c, doDestroy)
+	},
+}
+
+// destroyResult is a result of a step of 'destroy' on a resource. Action is
+// the name of the command which does the same
+type destroyResult struct {
+	Resource string
+	Action   string
+	Success  bool
+	Error    string      `json:",omitempty" toml:",omitempty"`
+	Result   interface{} `json:",omitempty" toml:",omitempty"`
+}
+
+// destroyTargets returns the declared servers, load balancers and images.
+// --target option restricts them and the targets must be declared
+func destroyTargets(c *cli.Context) (servers, lbs, images []string, err error) {
+	for name := range conf.Servers {
+		servers = append(servers, name)
+	}
+	sort.Strings(servers)
+	lbs = append(lbs, conf.LoadBalancers...)
+	images = append(images, conf.Images...)
+
+	targets := c.StringSlice("target")
+	if len(targets) == 0 {
+		return servers, lbs, images, nil
+	}
+	for _, t := range targets {
+		if !containsString(servers, t) && !containsString(lbs, t) && !containsString(images, t) {
+			return nil, nil, nil, errors.New("Couldn't find '" + t + "' in the config file")
+		}
+	}
+	restrict := func(names []string) []string {
+		var r []string
+		for _, name := range names {
+			if containsString(targets, name) {
+				r = append(r, name)
+			}
+		}
+		return r
+	}
+	return restrict(servers), restrict(lbs), restrict(images), nil
+}
+
+// existingResources fetches the resources in parallel and returns the names
+// of the ones which exist with their responses
+func existingResources(c *cli.Context, prefix string, names []string, newV func() interface{}) ([]string, []interface{}) {
+	vs := make([]interface{}, len(names))
+	errs := make([]error, len(names))
+	runParallel(len(names), c.Int("parallel"), func(i int) {
+		vs[i] = newV()
+		errs[i] = getResource(prefix+names[i], vs[i])
+	})
+
+	var existing []string
+	var found []interface{}
+	for i, err := range errs {
+		if e, ok := err.(*apiError); ok && e.StatusCode == 404 {
+			fmt.Fprintf(os.Stderr, "'%s' doesn't exist. Skipped\n", names[i])
+			continue
+		}
+		assert(err, names[i])
+		existing = append(existing, names[i])
+		found = append(found, vs[i])
+	}
+	return existing, found
+}
+
+func doDestroy(c *cli.Context) {
+	servers, lbs, images, err := destroyTargets(c)
+	assert(err)
+
+	servers, ves := existingResources(c, "/ve/", servers, func() interface{} { return &lib.Ve{} })
+	lbs, lbvs := existingResources(c, "/load-balancer/", lbs, func() interface{} { return &lib.LoadBalancer{} })
+	images, _ = existingResources(c, "/image/", images, func() interface{} { return &lib.VeImage{} })
+	if len(servers)+len(lbs)+len(images) == 0 {
+		fmt.Println("Nothing to destroy")
+		return
+	}
+
+	confirmOrExit(c, "destroy the resources permanently", func() []confirmTarget {
+		targets := describeVe(c, servers...)()
+		for _, name := range lbs {
+			targets = append(targets, lbTarget(name))
+		}
+		for _, name := range images {
+			targets = append(targets, imageTarget(name))
+		}
+		return targets
+	})
+
+	// load balancer and server pairs to be detached
+	var detach [][2]string
+	seen := make(map[[2]string]bool)
+	addDetach := func(lbname, vename string) {
+		p := [2]string{lbname, vename}
+		if len(lbname) > 0 && !seen[p] {
+			seen[p] = true
+			detach = append(detach, p)
+		}
+	}
+	for i, v := range ves {
+		addDetach(v.(*lib.Ve).LoadBalancer, servers[i])
+	}
+	for i, v := range lbvs {
+		for _, e := range v.(*lib.LoadBalancer).UsedBy {
+			addDetach(lbs[i], e.VeName)
+		}
+	}
+
+	opts := newWaitOptions(c)
+	var results []destroyResult
+	failed := make(map[string]bool)
+	// step does op on every resource in parallel and waits for the server
+	// which wait returns to satisfy the condition want
+	step := func(act string, names []string, op func(i int) (interface{}, error), want string, wait func(i int) string) {
+		vs, errs := runSteps(c, len(names), op, func(i int) error {
+			if len(want) == 0 {
+				return nil
+			}
+			return waitForOperation(wait(i), want, opts)
+		})
+		rs := make([]destroyResult, len(names))
+		for i, err := range errs {
+			rs[i] = destroyResult{Resource: names[i], Action: act, Success: err == nil, Result: vs[i]}
+			if err != nil {
+				rs[i].Error = strings.TrimSpace(err.Error())
+			}
+		}
+		results = append(results, rs...)
+	}
+	remaining := func(names []string) []string {
+		var r []string
+		for _, name := range names {
+			if !failed[name] {
+				r = append(r, name)
+			}
+		}
+		return r
+	}
+	markFailed := func(from int) {
+		for _, r := range results[from:] {
+			if !r.Success {
+				for _, name := range strings.Split(r.Resource, "/") {
+					failed[name] = true
+				}
+			}
+		}
+	}
+
+	n := len(results)
+	pairs := make([]string, len(detach))
+	for i, p := range detach {
+		pairs[i] = p[0] + "/" + p[1]
+	}
+	step("lbdetach", pairs, func(i int) (interface{}, error) {
+		resp, err := client.SendRequest("DELETE", "/load-balancer/"+pairs[i], nil)
+		if err != nil {
+			return nil, err
+		}
+		return newActionResult(pairs[i], "lbdetach", resp, 202)
+	}, waitSteady, func(i int) string { return detach[i][1] })
+	markFailed(n)
+
+	n = len(results)
+	servers = remaining(servers)
+	step("stop", servers, func(i int) (interface{}, error) {
+		return startStopVe(servers[i], "stop")
+	}, waitStopped, func(i int) string { return servers[i] })
+	markFailed(n)
+
+	n = len(results)
+	servers = remaining(servers)
+	step("delete", servers, func(i int) (interface{}, error) {
+		return deleteResource("/ve/", servers[i], "delete")
+	}, waitDeleted, func(i int) string { return servers[i] })
+	markFailed(n)
+
+	lbs = remaining(lbs)
+	step("lbdelete", lbs, func(i int) (interface{}, error) {
+		return deleteResource("/load-balancer/", lbs[i], "lbdelete")
+	}, "", nil)
+	step("imgdelete", images, func(i int) (interface{}, error) {
+		return deleteResource("/image/", images[i], "imgdelete")
+	}, "", nil)
+	if c.Bool("dry-run") {
+		return
+	}
+
+	outputResult(c, results, func(format string) {
+		printSummary(c, []string{"RESOURCE", "ACTION"}, len(results), func(i int) ([]interface{}, bool, interface{}, string) {
+			r := results[i]
+			return []interface{}{r.Resource, r.Action}, r.Success, r.Result, r.Error
+		})
+	})
+	for _, r := range results {
+		if !r.Success {
+			os.Exit(exitCodeError)
+		}
+	}
+}
+
+func deleteResource(prefix, name, act string) (interface{}, error) {
+	resp, err := client.SendRequest("DELETE", prefix+name, nil)
+	if err != nil {
+		return nil, err
+	}
+	return newActionResult(name, act, resp, 202)
+}
@@ -0,0 +1,139 @@
+package command
+
+import (
+	"fmt"
+
+	"github.com/codegangsta/cli"
+)
+
+var commandDiff = cli.Command{
+	Name:  "diff",
+	Usage: "Compare the configuration of Containers/Virtual machines",
+	Description: `
+	This command compares the configuration of two servers, or a server and its
+	spec in Pacifile with --against-spec option. CPU, RAM, bandwidth, disk size,
+	OS template, the number of IP addresses, backup schedule, installed
+	applications, firewall rules and autoscale rules are compared.
+
+	The result is printed as a unified diff. With '-o json', it's printed as JSON
+	Patch which changes the first one into the second one. When comparing with
+	the spec, only the values the spec defines are compared.
+`,
+	Flags: append(CommonFlags, againstSpecFlag, selectorFlag),
+	Action: func(c *cli.Context) {
+		action(c, doDiff)
+	},
+}
+
+// diffLine is a line of a unified diff. op is ' ', '-' or '+'
+type diffLine struct {
+	op   byte
+	text string
+}
+
+func doDiff(c *cli.Context) {
+	args := serverArgs(c, 0)
+	var from, to serverProfile
+	var fromName, toName string
+	if c.Bool("against-spec") {
+		if len(args) != 1 {
+			displayWrongNumOfArgsAndExit(c)
+		}
+		fromName = args.Get(0)
+		s, ok := conf.Servers[fromName]
+		if !ok {
+			displayErrorAndExit("Couldn't find the spec of '" + fromName + "' in " + c.String("config"))
+		}
+		toName = c.String("config") + ": " + fromName
+
+		p, err := fetchProfile(fromName)
+		assert(err, fromName)
+		from = p
+		to = specProfile(s)
+		to.fillUnset(from)
+	} else {
+		if len(args) != 2 {
+			displayWrongNumOfArgsAndExit(c)
+		}
+		fromName = args.Get(0)
+		toName = args.Get(1)
+
+		var errs [2]error
+		names := []string{fromName, toName}
+		profiles := make([]serverProfile, 2)
+		runParallel(2, 2, func(i int) {
+			profiles[i], errs[i] = fetchProfile(names[i])
+		})
+		for i, err := range errs {
+			assert(err, names[i])
+		}
+		from, to = profiles[0], profiles[1]
+	}
+
+	outputResult(c, profilePatch(from, to), func(format string) {
+		color := useColor(c)
+		fmt.Println(paintDiff(color, "--- "+fromName, ""))
+		fmt.Println(paintDiff(color, "+++ "+toName, ""))
+		for _, l := range diffLines(from.lines(), to.lines()) {
+			s := string(l.op) + l.text
+			switch l.op {
+			case '-':
+				s = paintDiff(color, s, colorRed)
+			case '+':
+				s = paintDiff(color, s, colorGreen)
+			}
+			fmt.Println(s)
+		}
+	})
+}
+
+func paintDiff(color bool, s, c string) string {
+	if !color {
+		return s
+	}
+	return paint(s, c)
+}
+
+// diffLines returns the lines of the unified diff from a to b with the full
+// context. It's based on the longest common subsequence of the lines
+func diffLines(a, b []string) []diffLine {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var lines []diffLine
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			lines = append(lines, diffLine{' ', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			lines = append(lines, diffLine{'-', a[i]})
+			i++
+		default:
+			lines = append(lines, diffLine{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		lines = append(lines, diffLine{'-', a[i]})
+	}
+	for ; j < len(b); j++ {
+		lines = append(lines, diffLine{'+', b[j]})
+	}
+	return lines
+}
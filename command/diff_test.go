@@ -0,0 +1,55 @@
+package command
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffLines(t *testing.T) {
+	for _, tt := range []struct {
+		a, b []string
+		want []diffLine
+	}{
+		{nil, nil, nil},
+		{
+			[]string{"a", "b"},
+			[]string{"a", "b"},
+			[]diffLine{{' ', "a"}, {' ', "b"}},
+		},
+		{
+			nil,
+			[]string{"a", "b"},
+			[]diffLine{{'+', "a"}, {'+', "b"}},
+		},
+		{
+			[]string{"a", "b"},
+			nil,
+			[]diffLine{{'-', "a"}, {'-', "b"}},
+		},
+		{
+			[]string{"a", "b", "c"},
+			[]string{"a", "c"},
+			[]diffLine{{' ', "a"}, {'-', "b"}, {' ', "c"}},
+		},
+		{
+			[]string{"a", "c"},
+			[]string{"a", "b", "c"},
+			[]diffLine{{' ', "a"}, {'+', "b"}, {' ', "c"}},
+		},
+		{
+			// a changed line is shown as removed and then added
+			[]string{"cpus: 1", "ram-size: 1024"},
+			[]string{"cpus: 2", "ram-size: 1024"},
+			[]diffLine{{'-', "cpus: 1"}, {'+', "cpus: 2"}, {' ', "ram-size: 1024"}},
+		},
+		{
+			[]string{"a", "b", "c", "d"},
+			[]string{"b", "x", "d", "e"},
+			[]diffLine{{'-', "a"}, {' ', "b"}, {'-', "c"}, {'+', "x"}, {' ', "d"}, {'+', "e"}},
+		},
+	} {
+		if got := diffLines(tt.a, tt.b); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("diffLines(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
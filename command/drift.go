@@ -0,0 +1,123 @@
+package command
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/codegangsta/cli"
+	"github.com/tsukaeru/pacicli/lib"
+)
+
+var commandDrift = cli.Command{
+	Name:  "drift",
+	Usage: "Detect Containers/Virtual machines which differ from their specs in Pacifile",
+	Description: `
+	This command compares every server declared in '[Servers.<name>]' sections
+	of Pacifile with the existing server and prints the differences. Spec,
+	Firewall and AutoscaleRule are compared with the server, its firewall rules
+	and autoscale rules and only the values the spec defines are compared. The
+	servers can be restricted by the <server_name> arguments.
+
+	It exits with 0 if all servers match their specs, 2 if any server differs
+	from its spec or doesn't exist and 1 if an error occurred. With '-o json',
+	the differences of every server are printed with the spec and the live
+	values.
+`,
+	Flags: append(CommonFlags, parallelFlag),
+	Action: func(c *cli.Context) {
+		action(c, doDrift)
+	},
+}
+
+// serverDrift is the differences of a server from its spec
+type serverDrift struct {
+	Server      string
+	Drifted     bool
+	Missing     bool
+	Error       string       `json:",omitempty" toml:",omitempty"`
+	Differences []driftField `json:",omitempty" toml:",omitempty"`
+	changes     []string
+}
+
+// driftField is a field of serverProfile whose live value differs from the spec
+type driftField struct {
+	Field string
+	Spec  interface{}
+	Live  interface{}
+}
+
+func doDrift(c *cli.Context) {
+	names, err := declaredServers(c.Args())
+	assert(err)
+
+	drifts := make([]serverDrift, len(names))
+	runParallel(len(names), c.Int("parallel"), func(i int) {
+		drifts[i] = detectDrift(names[i], conf.Servers[names[i]])
+	})
+
+	code := 0
+	for _, d := range drifts {
+		switch {
+		case len(d.Error) > 0:
+			code = exitCodeError
+		case d.Drifted && code == 0:
+			code = exitCodeDrift
+		}
+	}
+
+	outputResult(c, drifts, func(format string) {
+		color := useColor(c)
+		drifted := 0
+		for _, d := range drifts {
+			switch {
+			case len(d.Error) > 0:
+				fmt.Println(paintDiff(color, d.Server+": error: "+d.Error, colorRed))
+			case d.Missing:
+				drifted++
+				fmt.Println(paintDiff(color, d.Server+": missing", colorRed))
+			case d.Drifted:
+				drifted++
+				fmt.Println(paintDiff(color, d.Server+": drifted (spec -> live)", colorYellow))
+				for _, s := range d.changes {
+					fmt.Println("    " + s)
+				}
+			default:
+				fmt.Println(paintDiff(color, d.Server+": ok", colorGreen))
+			}
+		}
+		fmt.Printf("\n%d of %d servers drifted\n", drifted, len(drifts))
+	})
+	os.Exit(code)
+}
+
+// detectDrift compares the server with the spec s
+func detectDrift(vename string, s lib.Server) serverDrift {
+	d := serverDrift{Server: vename}
+	live, err := fetchProfile(vename)
+	if e, ok := err.(*apiError); ok && e.StatusCode == 404 {
+		d.Drifted, d.Missing = true, true
+		return d
+	}
+	if err != nil {
+		d.Error = strings.TrimSpace(err.Error())
+		return d
+	}
+
+	spec := specProfile(s)
+	spec.fillUnset(live)
+
+	sv := reflect.ValueOf(spec)
+	lv := reflect.ValueOf(live)
+	var names []string
+	for i, f := range spec.fields() {
+		if !reflect.DeepEqual(sv.Field(i).Interface(), lv.Field(i).Interface()) {
+			d.Differences = append(d.Differences, driftField{f.name, sv.Field(i).Interface(), lv.Field(i).Interface()})
+			names = append(names, f.name)
+		}
+	}
+	d.Drifted = len(d.Differences) > 0
+	d.changes = profileChanges(spec, live, names...)
+	return d
+}
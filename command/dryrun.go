@@ -0,0 +1,55 @@
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/BurntSushi/toml"
+
+	"github.com/codegangsta/cli"
+	"github.com/tsukaeru/pacicli/lib"
+)
+
+// dryRunRequest is a request which isn't sent because of --dry-run option
+type dryRunRequest struct {
+	Method string
+	URL    string
+	Body   string `json:",omitempty" toml:",omitempty"`
+}
+
+// printDryRun returns a function for lib.Client.DryRun which prints the method,
+// URL and pretty printed body of the request in the output format. A command
+// may send multiple requests and they are printed as they are made, so JSON
+// is printed one request per line (NDJSON) and TOML as '[[Requests]]' tables
+// which make one document together
+func printDryRun(c *cli.Context) func(method, url string, body []byte) {
+	var mu sync.Mutex
+	return func(method, url string, body []byte) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		r := dryRunRequest{Method: method, URL: url}
+		if len(body) > 0 {
+			if b, err := lib.IndentXML(body); err == nil {
+				body = b
+			}
+			r.Body = string(body)
+		}
+		switch strings.ToLower(c.String("output")) {
+		case "json":
+			b, err := json.Marshal(r)
+			assert(err)
+			fmt.Println(string(b))
+		case "toml":
+			assert(toml.NewEncoder(os.Stdout).Encode(map[string][]dryRunRequest{"Requests": {r}}))
+		default:
+			fmt.Println(r.Method, r.URL)
+			if len(r.Body) > 0 {
+				fmt.Println(r.Body)
+			}
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package command
+
+import (
+	"os"
+
+	"github.com/BurntSushi/toml"
+	"github.com/codegangsta/cli"
+	"github.com/tsukaeru/pacicli/lib"
+)
+
+var commandExport = cli.Command{
+	Name:  "export",
+	Usage: "Export Containers/Virtual machines as Pacifile entries",
+	Description: `
+	This command reads the configuration, firewall rules and autoscale rules of
+	the specified servers and prints them as '[Servers.<name>]' entries of
+	Pacifile. The output is TOML by default and JSON with '-o json'.
+
+	The entries can be used by 'create', 'fwcreate' and 'autoscale-create'
+	commands as they are. Values which only the API sets, like IDs and update
+	timestamps, aren't exported.
+
+	Glob patterns like 'web-*', --all option and --selector option like
+	'-l role=web' can be used to select the servers.
+`,
+	Flags: append(CommonFlags, allFlag, selectorFlag, parallelFlag),
+	Action: func(c *cli.Context) {
+		action(c, doExport)
+	},
+}
+
+func doExport(c *cli.Context) {
+	names := bulkTargets(c, c.Args())
+
+	servers := make([]lib.Server, len(names))
+	errs := make([]error, len(names))
+	runParallel(len(names), c.Int("parallel"), func(i int) {
+		servers[i], errs[i] = exportServer(names[i])
+	})
+	for i, err := range errs {
+		assert(err, names[i])
+	}
+
+	cfg := struct {
+		Servers map[string]lib.Server
+	}{Servers: make(map[string]lib.Server)}
+	for i, s := range servers {
+		cfg.Servers[names[i]] = s
+	}
+
+	outputResult(c, cfg, func(format string) {
+		assert(toml.NewEncoder(os.Stdout).Encode(cfg))
+	})
+}
+
+// exportServer builds a Pacifile server entry from the existing server
+func exportServer(vename string) (lib.Server, error) {
+	s := lib.Server{}
+
+	ve, err := getVe(vename)
+	if err != nil {
+		return s, err
+	}
+	s.Spec = veSpec(ve)
+
+	err = getResource("/ve/"+vename+"/firewall", &s.Firewall)
+	if e, ok := err.(*apiError); ok && e.StatusCode == 404 {
+		err = nil
+	}
+	if err != nil {
+		return s, err
+	}
+
+	autoscale, err := fetchAutoscale(vename)
+	if err != nil {
+		return s, err
+	}
+	if autoscale != nil && autoscale.Current != nil {
+		for _, r := range autoscale.Current.AutoscaleRule {
+			if r.Deleted != nil && *r.Deleted {
+				continue
+			}
+			// Those are set by the API
+			r.Deleted = nil
+			r.Version = nil
+			r.Updated = nil
+			r.UpdateDeliveredOk = nil
+			r.UpdateDelivered = nil
+			s.AutoscaleRule = append(s.AutoscaleRule, r)
+		}
+	}
+	return s, nil
+}
+
+// veSpec converts the existing server into the spec to create the same server
+func veSpec(ve lib.Ve) *lib.CreateVe {
+	spec := &lib.CreateVe{
+		Name:           ve.Name,
+		Hostname:       ve.Hostname,
+		Description:    ve.Description,
+		SubscriptionID: ve.SubscriptionID,
+		CPU:            ve.CPU,
+		RAMSize:        ve.RAMSize,
+		Bandwidth:      ve.Bandwidth,
+		NoOfPublicIP:   len(ve.Network.PublicIP),
+		NoOfPublicIPv6: len(ve.Network.PublicIP6),
+		Platform:       ve.Platform,
+	}
+	spec.VeDisk.Local = true
+	spec.VeDisk.Size = ve.VeDisk.Size
+	if len(ve.BackupSchedule.Name) > 0 {
+		spec.BackupSchedule = &struct {
+			Name string `xml:"name,attr"`
+		}{Name: ve.BackupSchedule.Name}
+	}
+	return spec
+}
@@ -0,0 +1,155 @@
+package command
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"path"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/codegangsta/cli"
+)
+
+// listFilter is a condition given by --filter option. It is applied to every
+// element of a list command result on the client side
+type listFilter struct {
+	key    string
+	negate bool
+	match  func(s string) bool
+}
+
+var filterOperators = []string{"!~", "!=", "~", "="}
+
+func parseListFilter(expr string) (listFilter, error) {
+	idx, op := -1, ""
+	for _, e := range filterOperators {
+		if i := strings.Index(expr, e); i > 0 && (idx == -1 || i < idx) {
+			idx, op = i, e
+		}
+	}
+	if idx == -1 {
+		return listFilter{}, errors.New("Invalid filter '" + expr + "'. It must be in KEY=VALUE, KEY!=VALUE, KEY~REGEXP or KEY!~REGEXP format")
+	}
+
+	f := listFilter{
+		key:    normalizeFilterKey(expr[:idx]),
+		negate: op[0] == '!',
+	}
+	value := expr[idx+len(op):]
+	switch op {
+	case "~", "!~":
+		re, err := regexp.Compile(value)
+		if err != nil {
+			return listFilter{}, err
+		}
+		f.match = re.MatchString
+	default:
+		pattern := strings.ToLower(value)
+		if _, err := path.Match(pattern, ""); err != nil {
+			return listFilter{}, errors.New("Invalid glob pattern '" + value + "' in filter")
+		}
+		f.match = func(s string) bool {
+			ok, _ := path.Match(pattern, strings.ToLower(s))
+			return ok
+		}
+	}
+	return f, nil
+}
+
+func normalizeFilterKey(s string) string {
+	return strings.NewReplacer("-", "", "_", "").Replace(strings.ToLower(strings.TrimSpace(s)))
+}
+
+// filterFieldValue returns a string representation of the field of struct v
+// specified by key. The key is compared with both the field name and its XML
+// name ignoring case, '-' and '_'. A key "foo" also matches "FooID" field
+func filterFieldValue(v reflect.Value, key string) (string, bool) {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v = reflect.Zero(v.Type().Elem())
+		} else {
+			v = v.Elem()
+		}
+	}
+	if v.Kind() != reflect.Struct {
+		return "", false
+	}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.Name == "XMLName" || len(sf.PkgPath) > 0 {
+			continue
+		}
+		xmlName := strings.Split(sf.Tag.Get("xml"), ",")[0]
+		names := []string{normalizeFilterKey(sf.Name), normalizeFilterKey(xmlName)}
+		for _, n := range names {
+			if n != key && n != key+"id" {
+				continue
+			}
+			fv := v.Field(i)
+			if fv.Kind() == reflect.Ptr {
+				if fv.IsNil() {
+					return "", true
+				}
+				fv = fv.Elem()
+			}
+			if tm, ok := fv.Interface().(encoding.TextMarshaler); ok {
+				b, _ := tm.MarshalText()
+				return string(b), true
+			}
+			return fmt.Sprint(fv.Interface()), true
+		}
+	}
+	return "", false
+}
+
+// filterList removes the elements which don't satisfy all filters from
+// the slice list points to
+func filterList(list interface{}, filters []listFilter) error {
+	if len(filters) == 0 {
+		return nil
+	}
+	lv := reflect.ValueOf(list).Elem()
+	zero := reflect.Zero(lv.Type().Elem())
+	for _, f := range filters {
+		if _, ok := filterFieldValue(zero, f.key); !ok {
+			return errors.New("Unknown filter key '" + f.key + "'")
+		}
+	}
+
+	filtered := reflect.MakeSlice(lv.Type(), 0, lv.Len())
+	for i := 0; i < lv.Len(); i++ {
+		elem := lv.Index(i)
+		ok := true
+		for _, f := range filters {
+			s, _ := filterFieldValue(elem, f.key)
+			if f.match(s) == f.negate {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			filtered = reflect.Append(filtered, elem)
+		}
+	}
+	lv.Set(filtered)
+	return nil
+}
+
+// applyListFilters filters the slice list points to with the conditions
+// given by --filter options. They can't be used with "xml" output format which
+// prints the raw response body
+func applyListFilters(c *cli.Context, list interface{}) {
+	if len(c.StringSlice("filter")) > 0 && strings.ToLower(c.String("output")) == "xml" {
+		displayErrorAndExit("--filter option can't be used with xml output format which prints the raw response")
+	}
+	var filters []listFilter
+	for _, e := range c.StringSlice("filter") {
+		f, err := parseListFilter(e)
+		assert(err)
+		filters = append(filters, f)
+	}
+	assert(filterList(list, filters))
+}
@@ -0,0 +1,95 @@
+package command
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseListFilterInvalid(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"state",
+		"=running",
+		"~web",
+		"name~[",
+		"name=[",
+	} {
+		if _, err := parseListFilter(expr); err == nil {
+			t.Errorf("parseListFilter(%q) didn't return an error", expr)
+		}
+	}
+}
+
+type testFilterCPU struct {
+	Number int `xml:"number,attr"`
+}
+
+type testFilterVe struct {
+	Name       string         `xml:"name,attr"`
+	State      string         `xml:"state,attr"`
+	SubnetID   int            `xml:"subnet-id,attr"`
+	CPU        *testFilterCPU `xml:"cpu"`
+	Production bool
+}
+
+func TestFilterList(t *testing.T) {
+	list := []testFilterVe{
+		{Name: "web1", State: "RUNNING", SubnetID: 1, CPU: &testFilterCPU{2}},
+		{Name: "web2", State: "STOPPED", SubnetID: 2},
+		{Name: "db1", State: "RUNNING", SubnetID: 1, Production: true},
+	}
+	for _, tt := range []struct {
+		exprs []string
+		want  []string
+	}{
+		{nil, []string{"web1", "web2", "db1"}},
+		{[]string{"state=running"}, []string{"web1", "db1"}},
+		{[]string{"State=Running"}, []string{"web1", "db1"}},
+		{[]string{"state!=running"}, []string{"web2"}},
+		{[]string{"name=web*"}, []string{"web1", "web2"}},
+		{[]string{"name~^web[0-9]$"}, []string{"web1", "web2"}},
+		{[]string{"name!~1$"}, []string{"web2"}},
+		{[]string{"name=web*", "state=running"}, []string{"web1"}},
+		// the key matches the XML name and the field name with 'id' suffix
+		{[]string{"subnet-id=1"}, []string{"web1", "db1"}},
+		{[]string{"subnet_id=2"}, []string{"web2"}},
+		{[]string{"subnet=2"}, []string{"web2"}},
+		{[]string{"production=true"}, []string{"db1"}},
+		{[]string{"cpu=2"}, []string{}},
+		{[]string{"cpu="}, []string{"web2", "db1"}},
+		// '=' in the value is a part of it
+		{[]string{"name=a=b"}, []string{}},
+	} {
+		var filters []listFilter
+		for _, e := range tt.exprs {
+			f, err := parseListFilter(e)
+			if err != nil {
+				t.Fatalf("parseListFilter(%q) returned an error: %v", e, err)
+			}
+			filters = append(filters, f)
+		}
+		l := append([]testFilterVe{}, list...)
+		if err := filterList(&l, filters); err != nil {
+			t.Errorf("%q: filterList returned an error: %v", tt.exprs, err)
+			continue
+		}
+		got := []string{}
+		for _, e := range l {
+			got = append(got, e.Name)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q: filterList = %q, want %q", tt.exprs, got, tt.want)
+		}
+	}
+}
+
+func TestFilterListUnknownKey(t *testing.T) {
+	f, err := parseListFilter("color=red")
+	if err != nil {
+		t.Fatalf("parseListFilter returned an error: %v", err)
+	}
+	l := []testFilterVe{{Name: "web1"}}
+	if err := filterList(&l, []listFilter{f}); err == nil {
+		t.Error("filterList didn't return an error for an unknown key")
+	}
+}
@@ -3,7 +3,7 @@ package command
 import (
 	"bytes"
 	"encoding/xml"
-	"fmt"
+	"errors"
 
 	"github.com/codegangsta/cli"
 	"github.com/tatsushid/go-prettytable"
@@ -18,7 +18,7 @@ var commandFirewallList = cli.Command{
 	The command obtains a list of existing firewall rules for the specified server.
 	The <server_name> must contain the server name.
 `,
-	Flags: append(CommonFlags, noHeaderFlag),
+	Flags: append(CommonFlags, showUnknownFlag, noHeaderFlag, selectorFlag),
 	Action: func(c *cli.Context) {
 		action(c, doFirewallList)
 	},
@@ -35,7 +35,7 @@ var commandFirewallCreate = cli.Command{
 	You have to use 'fwmodify' command instead of this when firewall rules has
 	already existed.
 `,
-	Flags: append(CommonFlags, settingFlag),
+	Flags: append(CommonFlags, settingFlag, selectorFlag),
 	Action: func(c *cli.Context) {
 		action(c, doFirewallCreateModify)
 	},
@@ -52,8 +52,12 @@ var commandFirewallModify = cli.Command{
 
 	The <server_name> must contain the server name and firewall rules must be
 	defined in Pacicli or --setting-file flag argument file.
+
+` + bulkHelp + `
+	The rules in --setting-file flag argument file are applied to all specified
+	servers. Otherwise the rules of each server are looked up in Pacifile.
 `,
-	Flags: append(CommonFlags, settingFlag),
+	Flags: append(CommonFlags, settingFlag, allFlag, selectorFlag, parallelFlag),
 	Action: func(c *cli.Context) {
 		action(c, doFirewallCreateModify)
 	},
@@ -66,18 +70,20 @@ var commandFirewallDelete = cli.Command{
 	The command deletes all existing firewall rules. To delete a specific rule,
 	retrieve all existing rules, modify the result set as needed and then use it
 	as an argument of the 'fwmodify' command.
-`,
-	Flags: CommonFlags,
+
+` + confirmHelp,
+	Flags: append(CommonFlags, yesFlag, selectorFlag),
 	Action: func(c *cli.Context) {
 		action(c, doFirewallDelete)
 	},
 }
 
 func doFirewallList(c *cli.Context) {
-	if len(c.Args()) == 0 {
+	args := serverArgs(c, 0)
+	if len(args) == 0 {
 		displayWrongNumOfArgsAndExit(c)
 	}
-	vename := c.Args().Get(0)
+	vename := args.Get(0)
 
 	resp, err := client.SendRequest("GET", "/ve/"+vename+"/firewall", nil)
 	assert(err)
@@ -88,8 +94,8 @@ func doFirewallList(c *cli.Context) {
 	fwlist := lib.Firewall{}
 	assert(xml.Unmarshal(resp.Body, &fwlist))
 
-	outputResult(c, fwlist, func(format string) {
-		tbl, err := prettytable.NewTable([]prettytable.Column{
+	outputResponse(c, resp, fwlist, func(format string) {
+		tbl := newTable(c, []prettytable.Column{
 			{Header: "ID", AlignRight: true},
 			{Header: "NAME"},
 			{Header: "PROTOCOL"},
@@ -97,10 +103,6 @@ func doFirewallList(c *cli.Context) {
 			{Header: "REMOTE_PORT", AlignRight: true},
 			{Header: "REMOTE_NET"},
 		}...)
-		assert(err)
-		if c.Bool("no-header") {
-			tbl.NoHeader = true
-		}
 		for _, e := range fwlist.Rule {
 			var ra lib.IPAddr
 			if len(e.RemoteNet) > 0 {
@@ -118,54 +120,67 @@ func doFirewallList(c *cli.Context) {
 }
 
 func doFirewallCreateModify(c *cli.Context) {
-	method := "PUT"
-	if c.Command.Name == "fwcreate" {
-		method = "POST"
+	var setting *lib.Firewall
+	if len(c.String("setting-file")) > 0 {
+		setting = new(lib.Firewall)
+		assert(lib.LoadConfig(c.String("setting-file"), setting))
 	}
 
-	if len(c.Args()) == 0 {
+	// Only fwmodify accepts multiple servers
+	var targets []string
+	if c.Command.Name == "fwmodify" {
+		targets = bulkTargets(c, c.Args())
+	} else if targets = serverArgs(c, 0); len(targets) != 1 {
 		displayWrongNumOfArgsAndExit(c)
 	}
-	vename := c.Args().Get(0)
 
-	var fw lib.Firewall
-	if len(c.String("setting-file")) > 0 {
-		assert(lib.LoadConfig(c.String("setting-file"), &fw))
-	} else {
-		if s, ok := conf.Servers[vename]; ok && len(s.Firewall.Rule) > 0 {
-			fw = s.Firewall
+	runBulk(c, targets, func(vename string) (interface{}, error) {
+		var fw lib.Firewall
+		if setting != nil {
+			fw = *setting
 		} else {
-			displayErrorAndExit("Couldn't find Firewall rules for '" + vename + "'")
+			if s, ok := conf.Servers[vename]; ok && len(s.Firewall.Rule) > 0 {
+				fw = s.Firewall
+			} else {
+				return nil, errors.New("Couldn't find Firewall rules for '" + vename + "'")
+			}
 		}
+		return sendFirewall(vename, c.Command.Name, fw)
+	}, printActionResult)
+}
+
+// sendFirewall creates the firewall rules of the server if act is 'fwcreate'
+// and replaces them otherwise
+func sendFirewall(vename, act string, fw lib.Firewall) (actionResult, error) {
+	method := "PUT"
+	if act == "fwcreate" {
+		method = "POST"
 	}
 
 	var b bytes.Buffer
-	assert(xml.NewEncoder(&b).Encode(fw))
+	if err := xml.NewEncoder(&b).Encode(fw); err != nil {
+		return actionResult{}, err
+	}
 
 	resp, err := client.SendRequest(method, "/ve/"+vename+"/firewall", &b)
-	assert(err)
-
-	switch resp.StatusCode {
-	case 200:
-		fmt.Println(string(resp.Body))
-	default:
-		displayErrorAndExit(string(resp.Body))
+	if err != nil {
+		return actionResult{}, err
 	}
+	return newActionResult(vename, act, resp, 200)
 }
 
 func doFirewallDelete(c *cli.Context) {
-	if len(c.Args()) == 0 {
+	args := serverArgs(c, 0)
+	if len(args) == 0 {
 		displayWrongNumOfArgsAndExit(c)
 	}
-	vename := c.Args().Get(0)
+	vename := args.Get(0)
+	confirmOrExit(c, "delete all firewall rules of the server", describeVe(c, vename))
 
 	resp, err := client.SendRequest("DELETE", "/ve/"+vename+"/firewall", nil)
 	assert(err)
 
-	switch resp.StatusCode {
-	case 200:
-		fmt.Println(string(resp.Body))
-	default:
-		displayErrorAndExit(string(resp.Body))
-	}
+	r, err := newActionResult(vename, c.Command.Name, resp, 200)
+	assert(err)
+	outputActionResult(c, r)
 }
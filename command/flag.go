@@ -6,7 +6,7 @@ import (
 )
 
 var CommonFlags = []cli.Flag{
-	configFileFlag, outputFlag,
+	configFileFlag, outputFlag, colorFlag, dryRunFlag,
 }
 
 var configFileFlag = cli.StringFlag{
@@ -19,7 +19,23 @@ var configFileFlag = cli.StringFlag{
 var outputFlag = cli.StringFlag{
 	Name:  "output, o",
 	Value: "text",
-	Usage: "Specify output format",
+	Usage: "Specify output format. It must be one of\n\t'text', 'json', 'toml' or 'xml'. 'xml' prints\n\tthe raw API response",
+}
+
+var colorFlag = cli.StringFlag{
+	Name:  "color",
+	Value: "auto",
+	Usage: "Specify when to color the output. It must be\n\tone of 'auto', 'always' or 'never'. 'auto'\n\tcolors it only if the output is a terminal and\n\tNO_COLOR environment variable isn't set",
+}
+
+var dryRunFlag = cli.BoolFlag{
+	Name:  "dry-run",
+	Usage: "Print the requests which change resources\n\tinstead of sending them. JSON output has one\n\trequest per line",
+}
+
+var showUnknownFlag = cli.BoolFlag{
+	Name:  "show-unknown",
+	Usage: "Report XML elements and attributes in the API\n\tresponse which pacicli doesn't know",
 }
 
 var verboseFlag = cli.BoolFlag{
@@ -47,24 +63,24 @@ var descriptionFlag = cli.StringFlag{
 	Usage: "Specify a server description",
 }
 
-var cpusFlag = cli.IntFlag{
+var cpusFlag = cli.StringFlag{
 	Name:  "cpus",
-	Usage: "Specify a number of CPU cores",
+	Usage: "Specify a number of CPU cores.\n\t'modify' accepts relative values like +1",
 }
 
-var cpuPowerFlag = cli.IntFlag{
+var cpuPowerFlag = cli.StringFlag{
 	Name:  "cpu-power",
-	Usage: "Specify CPU clock rate in Mhz",
+	Usage: "Specify CPU clock rate in Mhz.\n\t'modify' accepts relative values like +500",
 }
 
-var ramSizeFlag = cli.IntFlag{
+var ramSizeFlag = cli.StringFlag{
 	Name:  "ram-size, ram",
-	Usage: "Specify RAM size in MB",
+	Usage: "Specify RAM size in MB.\n\t'modify' accepts relative values like +512",
 }
 
-var bandwidthFlag = cli.IntFlag{
+var bandwidthFlag = cli.StringFlag{
 	Name:  "bandwidth",
-	Usage: "Specify bandwidth in kbps",
+	Usage: "Specify bandwidth in kbps.\n\t'modify' accepts relative values like -10%",
 }
 
 var addIPv4Flag = cli.IntFlag{
@@ -89,9 +105,110 @@ var dropIPv6Flag = cli.StringSliceFlag{
 	Usage: "Specify IPv6 addresses to be removed.\n\tYou can use this option more than once\n\tand can't use with --add-ipv6",
 }
 
-var diskSizeFlag = cli.IntFlag{
-	Name:  "disk-size",
-	Usage: "Specify Disk size in GB",
+var ipv4Flag = cli.IntFlag{
+	Name:  "ipv4",
+	Usage: "Specify a number of IPv4 addresses",
+}
+
+var ipv6Flag = cli.IntFlag{
+	Name:  "ipv6",
+	Usage: "Specify a number of IPv6 addresses",
+}
+
+var passwordFileFlag = cli.StringFlag{
+	Name:  "password-file",
+	Usage: "Write the generated password to the file\n\treadable only by the owner",
+}
+
+var passwordStoreFlag = cli.StringFlag{
+	Name:  "password-store",
+	Usage: "Write the generated password to a file named\n\tafter the server in the directory. VNC and load\n\tbalancer passwords have '.vnc' and '.lb' suffixes",
+}
+
+var noShowPasswordFlag = cli.BoolFlag{
+	Name:  "no-show-password",
+	Usage: "Don't output the generated password. It requires\n\t--password-file or --password-store and the password\n\tis output if it couldn't be saved",
+}
+
+var stateFlag = cli.StringFlag{
+	Name:  "state",
+	Usage: "Specify a condition to wait for. It must be one\n\tof 'running', 'stopped', 'deleted', 'created'\n\tor 'steady'",
+}
+
+var lbFlag = cli.StringFlag{
+	Name:  "lb",
+	Usage: "Wait for the load balancer instead of a server",
+}
+
+var appFlag = cli.StringFlag{
+	Name:  "app",
+	Usage: "Wait for the application in the server",
+}
+
+var allowIPDropFlag = cli.BoolFlag{
+	Name:  "allow-ip-drop",
+	Usage: "Drop the last IP addresses of a server which has\n\tmore addresses than its spec",
+}
+
+var appStateFlag = cli.StringFlag{
+	Name:  "app-state",
+	Value: "installed",
+	Usage: "Specify a condition of the application to wait\n\tfor. It must be 'installed' or 'uninstalled'",
+}
+
+var maxIntervalFlag = cli.IntFlag{
+	Name:  "max-interval",
+	Value: 60,
+	Usage: "Specify the maximum polling interval in seconds.\n\tThe interval is doubled after every poll",
+}
+
+var nextFlag = cli.IntFlag{
+	Name:  "next, n",
+	Value: 10,
+	Usage: "Specify a number of upcoming actions to show",
+}
+
+var retriesFlag = cli.IntFlag{
+	Name:  "retries",
+	Value: 3,
+	Usage: "Specify how many times to retry a failed request",
+}
+
+var retryIntervalFlag = cli.IntFlag{
+	Name:  "retry-interval",
+	Value: 30,
+	Usage: "Specify an interval in seconds between retries",
+}
+
+var targetFlag = cli.StringSliceFlag{
+	Name:  "target",
+	Value: &cli.StringSlice{},
+	Usage: "Restrict the resources to the named server, load\n\tbalancer or image. You can use this option\n\tmore than once",
+}
+
+var againstSpecFlag = cli.BoolFlag{
+	Name:  "against-spec",
+	Usage: "Compare the server with its spec in Pacifile",
+}
+
+var useIPv6Flag = cli.BoolFlag{
+	Name:  "ipv6",
+	Usage: "Use IPv6 address instead of IPv4",
+}
+
+var printFlag = cli.BoolFlag{
+	Name:  "print",
+	Usage: "Only print the resolved 'user@host'",
+}
+
+var backupScheduleFlag = cli.StringFlag{
+	Name:  "backup-schedule",
+	Usage: "Specify a backup schedule name",
+}
+
+var diskSizeFlag = cli.StringFlag{
+	Name:  "disk-size, disk",
+	Usage: "Specify Disk size in GB.\n\t'modify' accepts relative values like x2",
 }
 
 var customNsFlag = cli.BoolFlag{
@@ -139,3 +256,68 @@ var tailFlag = cli.IntFlag{
 	Name:  "tail",
 	Usage: "Specify the time in seconds at the end of\n\tthe average-period for which the averageing\n\tshould NOT be performed",
 }
+
+var filterFlag = cli.StringSliceFlag{
+	Name:  "filter",
+	Value: &cli.StringSlice{},
+	Usage: "Filter the result with KEY=VALUE, KEY!=VALUE,\n\tKEY~REGEXP or KEY!~REGEXP condition. VALUE can\n\tbe a glob pattern. You can use this option more\n\tthan once. It can't be used with -o xml",
+}
+
+var humanFlag = cli.BoolFlag{
+	Name:  "human",
+	Usage: "Show sizes, rates and CPU power in human\n\treadable units. It is the default when the\n\toutput is a terminal",
+}
+
+var noHumanFlag = cli.BoolFlag{
+	Name:  "no-human",
+	Usage: "Show sizes, rates and CPU power in raw units\n\twhich the API uses",
+}
+
+var wideFlag = cli.BoolFlag{
+	Name:  "wide, w",
+	Usage: "Show the details of every server like IP addresses,\n\tCPU, RAM, disk, OS template and load balancer",
+}
+
+var yesFlag = cli.BoolFlag{
+	Name:  "yes, y",
+	Usage: "Don't ask for confirmation",
+}
+
+var selectorFlag = cli.StringFlag{
+	Name:  "selector, l",
+	Usage: "Select servers by their labels like\n\t'role=web,env=prod'",
+}
+
+var allFlag = cli.BoolFlag{
+	Name:  "all",
+	Usage: "Do the operation on all servers",
+}
+
+var parallelFlag = cli.IntFlag{
+	Name:  "parallel, p",
+	Value: 4,
+	Usage: "Specify a number of API requests sent in parallel",
+}
+
+var waitFlag = cli.BoolFlag{
+	Name:  "wait",
+	Usage: "Wait until the operation completes",
+}
+
+var waitTimeoutFlag = cli.IntFlag{
+	Name:  "wait-timeout",
+	Value: 600,
+	Usage: "Specify how long to wait in seconds with --wait",
+}
+
+var pollIntervalFlag = cli.IntFlag{
+	Name:  "poll-interval",
+	Value: 5,
+	Usage: "Specify an interval in seconds to check the\n\tserver state while waiting",
+}
+
+var timeoutFlag = cli.IntFlag{
+	Name:  "timeout",
+	Value: 600,
+	Usage: "Specify how long to wait in seconds",
+}
@@ -2,7 +2,6 @@ package command
 
 import (
 	"encoding/xml"
-	"fmt"
 	"strconv"
 
 	"github.com/codegangsta/cli"
@@ -17,7 +16,7 @@ var commandImageList = cli.Command{
 	Description: `
 	This command obtains a list of the existing server images.
 `,
-	Flags: append(CommonFlags, noHeaderFlag),
+	Flags: append(CommonFlags, showUnknownFlag, filterFlag, noHeaderFlag),
 	Action: func(c *cli.Context) {
 		action(c, doImageList)
 	},
@@ -29,7 +28,7 @@ var commandImageInfo = cli.Command{
 	Description: `
 	This command obtains a detailed information for the specified server image.
 `,
-	Flags: CommonFlags,
+	Flags: append(CommonFlags, showUnknownFlag),
 	Action: func(c *cli.Context) {
 		action(c, doImageInfo)
 	},
@@ -47,7 +46,7 @@ var commandImageCreate = cli.Command{
 	If you have multiple subscriptions, you have to specify the subscription ID
 	by --subscription-id option. If not, it isn't required.
 `,
-	Flags: append(CommonFlags, subscriptionIDFlag),
+	Flags: append(CommonFlags, subscriptionIDFlag, selectorFlag),
 	Action: func(c *cli.Context) {
 		action(c, doImageCreate)
 	},
@@ -58,8 +57,9 @@ var commandImageDelete = cli.Command{
 	Usage: "Delete image",
 	Description: `
 	This command deletes an existing server image.
-`,
-	Flags: CommonFlags,
+
+` + confirmHelp,
+	Flags: append(CommonFlags, yesFlag),
 	Action: func(c *cli.Context) {
 		action(c, doImageDelete)
 	},
@@ -71,9 +71,10 @@ func doImageList(c *cli.Context) {
 
 	imglist := lib.ImageList{}
 	assert(xml.Unmarshal(resp.Body, &imglist))
+	applyListFilters(c, &imglist.ImageInfo)
 
-	outputResult(c, imglist, func(format string) {
-		tbl, err := prettytable.NewTable([]prettytable.Column{
+	outputResponse(c, resp, imglist, func(format string) {
+		tbl := newTable(c, []prettytable.Column{
 			{Header: "NAME"},
 			{Header: "SIZE", AlignRight: true},
 			{Header: "CREATED"},
@@ -81,10 +82,6 @@ func doImageList(c *cli.Context) {
 			{Header: "IMAGE_OF"},
 			{Header: "DESCRIPTION"},
 		}...)
-		assert(err)
-		if c.Bool("no-header") {
-			tbl.NoHeader = true
-		}
 		for _, e := range imglist.ImageInfo {
 			tbl.AddRow(e.Name, e.Size, e.Created, e.SubscriptionID, e.ImageOf, e.Description)
 		}
@@ -107,17 +104,18 @@ func doImageInfo(c *cli.Context) {
 	img := lib.VeImage{}
 	assert(xml.Unmarshal(resp.Body, &img))
 
-	outputResult(c, img, func(format string) {
+	outputResponse(c, resp, img, func(format string) {
 		lib.PrintXMLStruct(img)
 	})
 }
 
 func doImageCreate(c *cli.Context) {
-	if len(c.Args()) < 2 {
+	args := serverArgs(c, 0)
+	if len(args) < 2 {
 		displayWrongNumOfArgsAndExit(c)
 	}
-	vename := c.Args().Get(0)
-	imgname := c.Args().Get(1)
+	vename := args.Get(0)
+	imgname := args.Get(1)
 
 	path := "/image/" + vename
 	if c.Int("subscription-id") > 0 {
@@ -128,12 +126,9 @@ func doImageCreate(c *cli.Context) {
 	resp, err := client.SendRequest("POST", path, nil)
 	assert(err)
 
-	switch resp.StatusCode {
-	case 202:
-		fmt.Println(string(resp.Body))
-	default:
-		displayErrorAndExit(string(resp.Body))
-	}
+	r, err := newActionResult(imgname, c.Command.Name, resp, 202)
+	assert(err)
+	outputActionResult(c, r)
 }
 
 func doImageDelete(c *cli.Context) {
@@ -141,13 +136,11 @@ func doImageDelete(c *cli.Context) {
 		displayWrongNumOfArgsAndExit(c)
 	}
 	imgname := c.Args().Get(0)
+	confirmOrExit(c, "delete the image permanently", describeImage(imgname))
 
 	resp, err := client.SendRequest("DELETE", "/image/"+imgname, nil)
 	assert(err)
-	switch resp.StatusCode {
-	case 202:
-		fmt.Println(imgname, string(resp.Body))
-	default:
-		displayErrorAndExit(string(resp.Body))
-	}
+	r, err := newActionResult(imgname, c.Command.Name, resp, 202)
+	assert(err)
+	outputActionResult(c, r)
 }
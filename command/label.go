@@ -0,0 +1,170 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/codegangsta/cli"
+	"github.com/tsukaeru/pacicli/lib"
+)
+
+// labelRequirement is a condition of a label selector like "role=web" or
+// "env!=prod"
+type labelRequirement struct {
+	key    string
+	value  string
+	negate bool
+}
+
+// parseSelector parses comma separated label requirements. A server must meet
+// all of them to be selected
+func parseSelector(s string) ([]labelRequirement, error) {
+	var reqs []labelRequirement
+	for _, e := range strings.Split(s, ",") {
+		e = strings.TrimSpace(e)
+		if len(e) == 0 {
+			continue
+		}
+		r := labelRequirement{}
+		op := "="
+		if strings.Contains(e, "!=") {
+			op = "!="
+			r.negate = true
+		}
+		kv := strings.SplitN(e, op, 2)
+		if len(kv) == 2 {
+			r.key, r.value = strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1])
+		}
+		if len(kv) != 2 || len(r.key) == 0 {
+			return nil, errors.New("Invalid label selector '" + e + "'. It must be like 'key=value' or 'key!=value'")
+		}
+		reqs = append(reqs, r)
+	}
+	return reqs, nil
+}
+
+func matchLabels(reqs []labelRequirement, labels map[string]string) bool {
+	for _, r := range reqs {
+		v, ok := labels[r.key]
+		if r.negate == (ok && v == r.value) {
+			return false
+		}
+	}
+	return true
+}
+
+// serverLabels returns the labels of all servers. They are read from Labels of
+// the servers in Pacifile and the labels file which LabelsFile in Pacifile
+// specifies. The labels file takes precedence. A relative LabelsFile is
+// resolved against the directory of Pacifile by action
+func serverLabels() (map[string]map[string]string, error) {
+	labels := make(map[string]map[string]string)
+	set := func(name string, l map[string]string) {
+		if labels[name] == nil {
+			labels[name] = make(map[string]string)
+		}
+		for k, v := range l {
+			labels[name][k] = v
+		}
+	}
+	for name, s := range conf.Servers {
+		set(name, s.Labels)
+	}
+	if len(conf.LabelsFile) > 0 {
+		var file map[string]map[string]string
+		if err := lib.LoadConfig(conf.LabelsFile, &file); err != nil {
+			return nil, err
+		}
+		for name, l := range file {
+			set(name, l)
+		}
+	}
+	return labels, nil
+}
+
+// formatLabels formats labels as "key=value" pairs sorted by the key
+func formatLabels(labels map[string]string) string {
+	pairs := make([]string, 0, len(labels))
+	for k, v := range labels {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// serverArgs returns the arguments of a command which takes a server name at
+// index i. With --selector option, the server is selected by its labels instead
+// of the argument, and it must select exactly one server
+func serverArgs(c *cli.Context, i int) cli.Args {
+	args := c.Args()
+	if len(c.String("selector")) == 0 {
+		return args
+	}
+	names, err := resolveServers(nil, false, c.String("selector"))
+	assert(err)
+	if len(names) != 1 {
+		displayErrorAndExit(fmt.Sprintf("The selector '%s' must select exactly one server, but it selected %d: %s", c.String("selector"), len(names), strings.Join(names, ",")))
+	}
+	if i > len(args) {
+		displayWrongNumOfArgsAndExit(c)
+	}
+	r := append(cli.Args{}, args[:i]...)
+	r = append(r, names[0])
+	return append(r, args[i:]...)
+}
+
+// hasLabels reports whether any server in velist has a label. The lists show
+// LABELS column only in that case
+func hasLabels(velist lib.VeList, labels map[string]map[string]string) bool {
+	for _, e := range velist.VeInfo {
+		if len(labels[e.Name]) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// applySelector removes the servers which don't match --selector option from
+// velist
+func applySelector(c *cli.Context, velist *lib.VeList, labels map[string]map[string]string) error {
+	if len(c.String("selector")) == 0 {
+		return nil
+	}
+	reqs, err := parseSelector(c.String("selector"))
+	if err != nil {
+		return err
+	}
+	selected := velist.VeInfo[:0]
+	for _, e := range velist.VeInfo {
+		if matchLabels(reqs, labels[e.Name]) {
+			selected = append(selected, e)
+		}
+	}
+	velist.VeInfo = selected
+	return nil
+}
+
+// selectByLabels returns the names which match selector. It returns names as
+// they are if selector is empty
+func selectByLabels(selector string, names []string) ([]string, error) {
+	if len(selector) == 0 {
+		return names, nil
+	}
+	reqs, err := parseSelector(selector)
+	if err != nil {
+		return nil, err
+	}
+	labels, err := serverLabels()
+	if err != nil {
+		return nil, err
+	}
+	var selected []string
+	for _, name := range names {
+		if matchLabels(reqs, labels[name]) {
+			selected = append(selected, name)
+		}
+	}
+	return selected, nil
+}
@@ -0,0 +1,76 @@
+package command
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseSelector(t *testing.T) {
+	for _, tt := range []struct {
+		s    string
+		want []labelRequirement
+	}{
+		{"", nil},
+		{"role=web", []labelRequirement{{"role", "web", false}}},
+		{"env!=prod", []labelRequirement{{"env", "prod", true}}},
+		{"role=web,env!=prod", []labelRequirement{{"role", "web", false}, {"env", "prod", true}}},
+		{" role = web , env != prod ", []labelRequirement{{"role", "web", false}, {"env", "prod", true}}},
+		{"role=web,", []labelRequirement{{"role", "web", false}}},
+		{"role=", []labelRequirement{{"role", "", false}}},
+		{"expr=a=b", []labelRequirement{{"expr", "a=b", false}}},
+	} {
+		got, err := parseSelector(tt.s)
+		if err != nil {
+			t.Errorf("parseSelector(%q) returned an error: %v", tt.s, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseSelector(%q) = %+v, want %+v", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestParseSelectorInvalid(t *testing.T) {
+	for _, s := range []string{
+		"role",
+		"=web",
+		" = web",
+		"!=prod",
+		"role=web,env",
+	} {
+		if _, err := parseSelector(s); err == nil {
+			t.Errorf("parseSelector(%q) didn't return an error", s)
+		}
+	}
+}
+
+func TestMatchLabels(t *testing.T) {
+	labels := map[string]string{"role": "web", "env": "prod"}
+	for _, tt := range []struct {
+		s      string
+		labels map[string]string
+		want   bool
+	}{
+		{"", labels, true},
+		{"role=web", labels, true},
+		{"role=db", labels, false},
+		{"role=web,env=prod", labels, true},
+		{"role=web,env=dev", labels, false},
+		{"env!=dev", labels, true},
+		{"env!=prod", labels, false},
+		// a missing label doesn't equal any value
+		{"zone=a", labels, false},
+		{"zone!=a", labels, true},
+		{"zone=", labels, false},
+		{"role=web", nil, false},
+		{"role!=web", nil, true},
+	} {
+		reqs, err := parseSelector(tt.s)
+		if err != nil {
+			t.Fatalf("parseSelector(%q) returned an error: %v", tt.s, err)
+		}
+		if got := matchLabels(reqs, tt.labels); got != tt.want {
+			t.Errorf("matchLabels(%q, %v) = %v, want %v", tt.s, tt.labels, got, tt.want)
+		}
+	}
+}
@@ -17,7 +17,7 @@ var commandLbList = cli.Command{
 	Description: `
 	This command obtains a list of the available load balancers.
 `,
-	Flags: append(CommonFlags, noHeaderFlag),
+	Flags: append(CommonFlags, showUnknownFlag, filterFlag, noHeaderFlag),
 	Action: func(c *cli.Context) {
 		action(c, doLbList)
 	},
@@ -30,7 +30,7 @@ var commandLbInfo = cli.Command{
 	This command obtains the information about a specified load balancer. The
 	<lb_name> argument must contain the load balancer name.
 `,
-	Flags: append(CommonFlags, verboseFlag),
+	Flags: append(CommonFlags, showUnknownFlag, verboseFlag),
 	Action: func(c *cli.Context) {
 		action(c, doLbInfo)
 	},
@@ -45,7 +45,7 @@ var commandLbHistory = cli.Command{
 	the name of the load balancer for which to retrieve the history and -n option
 	must be used to specify the number of records to be included in the result set.
 `,
-	Flags: append(CommonFlags, numRecordsFlag, verboseFlag, noHeaderFlag),
+	Flags: append(CommonFlags, showUnknownFlag, numRecordsFlag, verboseFlag, noHeaderFlag, humanFlag, noHumanFlag),
 	Action: func(c *cli.Context) {
 		action(c, doLbHistory)
 	},
@@ -61,7 +61,7 @@ var commandLbCreate = cli.Command{
 	If you have multiple subscriptions, you have to specify the subscription ID
 	by --subscription-id option. If not, it isn't required.
 `,
-	Flags: append(CommonFlags, subscriptionIDFlag),
+	Flags: append(CommonFlags, subscriptionIDFlag, passwordFileFlag, passwordStoreFlag, noShowPasswordFlag),
 	Action: func(c *cli.Context) {
 		action(c, doLbCreate)
 	},
@@ -86,8 +86,9 @@ var commandLbDelete = cli.Command{
 	This command deletes an existing load balancer. If there are servers attached
 	to a load balancer, the deletion fails. In such a case, the servers have to be
 	detached first.
-`,
-	Flags: CommonFlags,
+
+` + confirmHelp,
+	Flags: append(CommonFlags, yesFlag),
 	Action: func(c *cli.Context) {
 		action(c, doLbDelete)
 	},
@@ -102,7 +103,7 @@ var commandLbAttach = cli.Command{
 	The <lb_name> and <server_name> must contain the load balancer and the server
 	names respectively.
 `,
-	Flags: CommonFlags,
+	Flags: append(CommonFlags, waitFlag, waitTimeoutFlag, pollIntervalFlag, selectorFlag),
 	Action: func(c *cli.Context) {
 		action(c, doLbAttachDetach)
 	},
@@ -115,7 +116,7 @@ var commandLbDetach = cli.Command{
 	The command detaches a server from a load balancer. The <lb_name> and
 	<server_name> must contain the load balancer and the server names respectively.
 `,
-	Flags: CommonFlags,
+	Flags: append(CommonFlags, selectorFlag),
 	Action: func(c *cli.Context) {
 		action(c, doLbAttachDetach)
 	},
@@ -127,15 +128,13 @@ func doLbList(c *cli.Context) {
 
 	lblist := lib.LbList{}
 	assert(xml.Unmarshal(resp.Body, &lblist))
+	applyListFilters(c, &lblist.LoadBalancer)
 
-	outputResult(c, lblist, func(format string) {
-		tbl, err := prettytable.NewTable([]prettytable.Column{
+	outputResponse(c, resp, lblist, func(format string) {
+		tbl := newTable(c, []prettytable.Column{
 			{Header: "NAME"}, {Header: "STATE"}, {Header: "SUBSCR_ID", AlignRight: true},
 		}...)
-		assert(err)
-		if c.Bool("no-header") {
-			tbl.NoHeader = true
-		}
+		tbl.colorize(1, stateColor)
 		for _, e := range lblist.LoadBalancer {
 			tbl.AddRow(e.Name, e.State, e.SubscriptionID)
 		}
@@ -158,7 +157,7 @@ func doLbInfo(c *cli.Context) {
 	lb := lib.LoadBalancer{}
 	assert(xml.Unmarshal(resp.Body, &lb))
 
-	outputResult(c, lb, func(format string) {
+	outputResponse(c, resp, lb, func(format string) {
 		if c.Bool("verbose") {
 			lib.PrintXMLStruct(lb)
 		} else {
@@ -173,11 +172,10 @@ func doLbInfo(c *cli.Context) {
 			fmt.Printf("           Status: %s\n\n", lb.State)
 			fmt.Println("BALANCED SERVERS")
 
-			tbl, err := prettytable.NewTable([]prettytable.Column{
+			tbl := newTable(c, []prettytable.Column{
 				{Header: "NAME"},
 				{Header: "IPADDR"},
 			}...)
-			assert(err)
 			for _, e := range lb.UsedBy {
 				tbl.AddRow(e.VeName, e.IP)
 			}
@@ -206,28 +204,11 @@ func doLbHistory(c *cli.Context) {
 	hst := lib.VeHistory{}
 	assert(xml.Unmarshal(resp.Body, &hst))
 
-	outputResult(c, hst, func(format string) {
+	outputResponse(c, resp, hst, func(format string) {
 		if c.Bool("verbose") {
 			lib.PrintXMLStruct(hst)
 		} else {
-			tbl, err := prettytable.NewTable([]prettytable.Column{
-				{Header: "DATETIME"},
-				{Header: "CPU", AlignRight: true},
-				{Header: "MEMORY", AlignRight: true},
-				{Header: "DISK", AlignRight: true},
-				{Header: "BANDWIDTH", AlignRight: true},
-				{Header: "PUB_IPS", AlignRight: true},
-				{Header: "STATUS"},
-			}...)
-			assert(err)
-			if c.Bool("no-header") {
-				tbl.NoHeader = true
-			}
-			for _, e := range hst.VeSnapshot {
-				ts, _ := e.EventTimestamp.MarshalText()
-				tbl.AddRow(ts, e.CPU, e.RAM, e.LocalDisk, e.Bandwidth, e.NoOfPublicIP, e.State)
-			}
-			tbl.Print()
+			printVeHistoryTable(c, hst)
 		}
 	})
 }
@@ -253,10 +234,8 @@ func doLbCreate(c *cli.Context) {
 
 	pwd := lib.PasswordResponse{}
 	assert(xml.Unmarshal(resp.Body, &pwd))
-
-	outputResult(c, pwd, func(format string) {
-		lib.PrintXMLStruct(pwd)
-	})
+	pwd, err = savePassword(c, lbname, passwordLb, pwd)
+	outputPassword(c, pwd, err)
 }
 
 func doLbRestart(c *cli.Context) {
@@ -267,12 +246,9 @@ func doLbRestart(c *cli.Context) {
 
 	resp, err := client.SendRequest("PUT", "/load-balancer/"+lbname+"/restart", nil)
 	assert(err)
-	switch resp.StatusCode {
-	case 202:
-		fmt.Println(lbname, string(resp.Body))
-	default:
-		displayErrorAndExit(string(resp.Body))
-	}
+	r, err := newActionResult(lbname, c.Command.Name, resp, 202)
+	assert(err)
+	outputActionResult(c, r)
 }
 
 func doLbDelete(c *cli.Context) {
@@ -280,23 +256,22 @@ func doLbDelete(c *cli.Context) {
 		displayWrongNumOfArgsAndExit(c)
 	}
 	lbname := c.Args().Get(0)
+	confirmOrExit(c, "delete the load balancer permanently", describeLb(lbname))
 
 	resp, err := client.SendRequest("DELETE", "/load-balancer/"+lbname, nil)
 	assert(err)
-	switch resp.StatusCode {
-	case 202:
-		fmt.Println(lbname, string(resp.Body))
-	default:
-		displayErrorAndExit(string(resp.Body))
-	}
+	r, err := newActionResult(lbname, c.Command.Name, resp, 202)
+	assert(err)
+	outputActionResult(c, r)
 }
 
 func doLbAttachDetach(c *cli.Context) {
-	if len(c.Args()) < 2 {
+	args := serverArgs(c, 1)
+	if len(args) < 2 {
 		displayWrongNumOfArgsAndExit(c)
 	}
-	lbname := c.Args().Get(0)
-	vename := c.Args().Get(1)
+	lbname := args.Get(0)
+	vename := args.Get(1)
 
 	method := "POST"
 	if c.Command.Name == "lbdetach" {
@@ -305,10 +280,9 @@ func doLbAttachDetach(c *cli.Context) {
 	resp, err := client.SendRequest(method, "/load-balancer/"+lbname+"/"+vename, nil)
 	assert(err)
 
-	switch resp.StatusCode {
-	case 202:
-		fmt.Println(string(resp.Body))
-	default:
-		displayErrorAndExit(string(resp.Body))
-	}
+	r, err := newActionResult(lbname+"/"+vename, c.Command.Name, resp, 202)
+	assert(err)
+	outputActionResult(c, r)
+
+	waitIfRequested(c, vename, waitSteady)
 }
@@ -0,0 +1,162 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/codegangsta/cli"
+	"github.com/tsukaeru/pacicli/lib"
+)
+
+// resourceOption is an option which specifies a server resource value and
+// the current value of the resource in lib.Ve
+type resourceOption struct {
+	name    string
+	metric  string
+	current func(ve lib.Ve) int
+}
+
+// Resource options which 'modify' command accepts relative values for. metric
+// is the autoscale metric name which limits the resource
+var resourceOptions = []resourceOption{
+	{"cpus", "", func(ve lib.Ve) int { return ve.CPU.Number }},
+	{"cpu-power", "cpu", func(ve lib.Ve) int { return ve.CPU.Power }},
+	{"ram-size", "ram", func(ve lib.Ve) int { return ve.RAMSize }},
+	{"bandwidth", "bandwidth", func(ve lib.Ve) int { return ve.Bandwidth }},
+	{"disk-size", "", func(ve lib.Ve) int { return ve.VeDisk.Size }},
+}
+
+// isRelativeValue reports whether s is a difference like '+512' or '-10%' or
+// a multiplier like 'x2'
+func isRelativeValue(s string) bool {
+	return strings.HasPrefix(s, "+") || strings.HasPrefix(s, "-") ||
+		strings.HasPrefix(s, "x") || strings.HasPrefix(s, "*")
+}
+
+// resolveValue returns the value which s specifies. A relative value is
+// resolved against current
+func resolveValue(s string, current int) (int, error) {
+	var v float64
+	switch {
+	case strings.HasPrefix(s, "x"), strings.HasPrefix(s, "*"):
+		f, err := strconv.ParseFloat(s[1:], 64)
+		if err != nil {
+			return 0, err
+		}
+		v = float64(current) * f
+	case strings.HasPrefix(s, "+"), strings.HasPrefix(s, "-"):
+		sign := 1.0
+		if s[0] == '-' {
+			sign = -1.0
+		}
+		if strings.HasSuffix(s, "%") {
+			f, err := strconv.ParseFloat(s[1:len(s)-1], 64)
+			if err != nil {
+				return 0, err
+			}
+			v = float64(current) * (1 + sign*f/100)
+		} else {
+			f, err := strconv.ParseFloat(s[1:], 64)
+			if err != nil {
+				return 0, err
+			}
+			v = float64(current) + sign*f
+		}
+	default:
+		n, err := strconv.Atoi(s)
+		return n, err
+	}
+	return int(v + 0.5), nil
+}
+
+// absoluteOption returns the value of a resource option which must be an
+// absolute number. It returns 0 if the option isn't specified
+func absoluteOption(c *cli.Context, name string) int {
+	s := c.String(name)
+	if len(s) == 0 {
+		return 0
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 {
+		displayErrorAndExit("Invalid --" + name + " value '" + s + "'. It must be a number")
+	}
+	return n
+}
+
+// resolveResourceOptions returns the values of the resource options which are
+// specified. Relative values are resolved against the current server spec and
+// the values are validated against the limits of its autoscale rules
+func resolveResourceOptions(c *cli.Context, vename string) (map[string]int, error) {
+	values := make(map[string]int)
+	var ve *lib.Ve
+	for _, o := range resourceOptions {
+		s := c.String(o.name)
+		if len(s) == 0 {
+			continue
+		}
+		current := 0
+		if isRelativeValue(s) {
+			if ve == nil {
+				v, err := getVe(vename)
+				if err != nil {
+					return nil, err
+				}
+				ve = &v
+			}
+			current = o.current(*ve)
+		}
+		v, err := resolveValue(s, current)
+		if err != nil {
+			return nil, errors.New("Invalid --" + o.name + " value '" + s + "'")
+		}
+		if v <= 0 {
+			return nil, fmt.Errorf("--%s value '%s' results in %d. It must be greater than 0", o.name, s, v)
+		}
+		values[o.name] = v
+	}
+
+	if err := validateAutoscaleLimits(vename, values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// validateAutoscaleLimits checks that the values are within the limits of the
+// autoscale rules of the server. A server without autoscale rules is skipped
+func validateAutoscaleLimits(vename string, values map[string]int) error {
+	limited := false
+	for _, o := range resourceOptions {
+		if _, ok := values[o.name]; ok && len(o.metric) > 0 {
+			limited = true
+		}
+	}
+	if !limited {
+		return nil
+	}
+
+	autoscale, err := fetchAutoscale(vename)
+	if err != nil {
+		return err
+	}
+	if autoscale == nil || autoscale.Current == nil {
+		return nil
+	}
+
+	for _, o := range resourceOptions {
+		v, ok := values[o.name]
+		if !ok || len(o.metric) == 0 {
+			continue
+		}
+		for _, r := range autoscale.Current.AutoscaleRule {
+			if !strings.EqualFold(r.Metric, o.metric) || r.Limits == nil {
+				continue
+			}
+			if v < r.Limits.Min || v > r.Limits.Max {
+				return fmt.Errorf("--%s value %d is out of the autoscale limits %d-%d of '%s' metric", o.name, v, r.Limits.Min, r.Limits.Max, r.Metric)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package command
+
+import "testing"
+
+func TestIsRelativeValue(t *testing.T) {
+	for _, tt := range []struct {
+		s    string
+		want bool
+	}{
+		{"512", false},
+		{"+512", true},
+		{"-1", true},
+		{"-10%", true},
+		{"x2", true},
+		{"*1.5", true},
+	} {
+		if got := isRelativeValue(tt.s); got != tt.want {
+			t.Errorf("isRelativeValue(%q) = %v, want %v", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestResolveValue(t *testing.T) {
+	for _, tt := range []struct {
+		s       string
+		current int
+		want    int
+	}{
+		{"2048", 1024, 2048},
+		{"+512", 1024, 1536},
+		{"-256", 1024, 768},
+		{"+1", 1, 2},
+		{"x2", 20, 40},
+		{"*1.5", 3, 5},
+		{"x0.5", 1000, 500},
+		{"+10%", 1000, 1100},
+		{"-10%", 1000, 900},
+		{"+50%", 3, 5},
+	} {
+		got, err := resolveValue(tt.s, tt.current)
+		if err != nil {
+			t.Errorf("resolveValue(%q, %d) returned an error: %v", tt.s, tt.current, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("resolveValue(%q, %d) = %d, want %d", tt.s, tt.current, got, tt.want)
+		}
+	}
+}
+
+func TestResolveValueInvalid(t *testing.T) {
+	for _, s := range []string{
+		"",
+		"ram",
+		"+",
+		"+a",
+		"-%",
+		"x",
+		"xx2",
+		"1.5",
+	} {
+		if _, err := resolveValue(s, 1024); err == nil {
+			t.Errorf("resolveValue(%q) didn't return an error", s)
+		}
+	}
+}
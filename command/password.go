@@ -0,0 +1,112 @@
+package command
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/codegangsta/cli"
+	"github.com/tsukaeru/pacicli/lib"
+)
+
+// Kinds of generated passwords. They are used as the suffix of the file name in
+// the password store so that a VNC password doesn't overwrite the administrator
+// password of the same server, and a load balancer password doesn't overwrite
+// the password of the server with the same name
+const (
+	passwordAdmin = ""
+	passwordVnc   = "vnc"
+	passwordLb    = "lb"
+)
+
+// passwordSaveError is returned by savePassword with the password which
+// couldn't be saved. The password must be output with the error because it
+// can't be retrieved again
+type passwordSaveError struct {
+	err error
+}
+
+func (e *passwordSaveError) Error() string {
+	return "Couldn't save the password: " + e.err.Error()
+}
+
+// assertPasswordOptions exits if --no-show-password option is specified
+// without a place to save the password, which would lose it
+func assertPasswordOptions(c *cli.Context) {
+	if c.Bool("no-show-password") && len(c.String("password-file")) == 0 && len(c.String("password-store")) == 0 {
+		displayErrorAndExit("--no-show-password option requires --password-file or --password-store option")
+	}
+}
+
+// savePassword writes the generated password of the resource to the files
+// which --password-file and --password-store options specify. It returns pwd
+// to be output, whose password is cleared if --no-show-password is specified.
+// If it fails to save the password, pwd is returned as it is with
+// a *passwordSaveError
+func savePassword(c *cli.Context, name, kind string, pwd lib.PasswordResponse) (lib.PasswordResponse, error) {
+	if len(c.String("password-file")) > 0 {
+		if err := writeFileAtomic(c.String("password-file"), []byte(pwd.Password+"\n")); err != nil {
+			return pwd, &passwordSaveError{err}
+		}
+	}
+	if dir := c.String("password-store"); len(dir) > 0 {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return pwd, &passwordSaveError{err}
+		}
+		fname := name
+		if len(kind) > 0 {
+			fname += "." + kind
+		}
+		if err := writeFileAtomic(filepath.Join(dir, fname), []byte(pwd.Password+"\n")); err != nil {
+			return pwd, &passwordSaveError{err}
+		}
+	}
+	if c.Bool("no-show-password") {
+		pwd.Password = ""
+	}
+	return pwd, nil
+}
+
+// outputPassword outputs pwd and then exits if err isn't nil. pwd is output
+// even if savePassword failed so that the password isn't lost
+func outputPassword(c *cli.Context, pwd lib.PasswordResponse, err error) {
+	outputResult(c, pwd, func(format string) {
+		lib.PrintXMLStruct(pwd)
+	})
+	assert(err)
+}
+
+// checkPasswordFile exits if --password-file option is used for multiple
+// resources because they would overwrite the file with each other
+func checkPasswordFile(c *cli.Context, n int) {
+	if n > 1 && len(c.String("password-file")) > 0 {
+		displayErrorAndExit("--password-file option can't be used for multiple servers. Please use --password-store option instead")
+	}
+}
+
+// writeFileAtomic writes b to a temporary file readable only by the owner and
+// renames it to fpath so that a partially written file is never seen
+func writeFileAtomic(fpath string, b []byte) error {
+	f, err := ioutil.TempFile(filepath.Dir(fpath), "."+filepath.Base(fpath)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if err := f.Chmod(0600); err != nil {
+		f.Close()
+		return err
+	}
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), fpath)
+}
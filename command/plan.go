@@ -0,0 +1,476 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/codegangsta/cli"
+	"github.com/tsukaeru/pacicli/lib"
+)
+
+var commandPlan = cli.Command{
+	Name:  "plan",
+	Usage: "Show changes to bring Containers/Virtual machines to their specs in Pacifile",
+	Description: `
+	This command compares every server declared in '[Servers.<name>]' sections
+	of Pacifile with the existing server and lists the requests which 'apply'
+	command sends. A server which doesn't exist is created from its Spec. For an
+	existing server, CPU, RAM, bandwidth, disk size, the number of IP addresses,
+	backup schedule, firewall rules and autoscale rules are compared and only
+	the values the spec defines are changed.
+
+	The servers can be restricted by the <server_name> arguments. Differences
+	which can't be applied, like OS template, are shown as warnings. Fewer IP
+	addresses than the existing ones are also shown as a warning unless
+	--allow-ip-drop option is specified, because the last addresses of the
+	server are dropped.
+`,
+	Flags: append(CommonFlags, parallelFlag, allowIPDropFlag),
+	Action: func(c *cli.Context) {
+		action(c, doPlan)
+	},
+}
+
+var commandApply = cli.Command{
+	Name:  "apply",
+	Usage: "Bring Containers/Virtual machines to their specs in Pacifile",
+	Description: `
+	This command sends the requests which 'plan' command lists in the order. A
+	created server is waited for before its firewall and autoscale rules are
+	created and a modified server is waited for until it's steady. It stops at
+	the first failure.
+
+	It asks for confirmation showing the plan unless --yes option is specified.
+	The generated passwords of the created servers can be kept out of the
+	output by --password-store and --no-show-password options.
+`,
+	Flags: append(CommonFlags, parallelFlag, allowIPDropFlag, yesFlag, waitTimeoutFlag, pollIntervalFlag, passwordStoreFlag, noShowPasswordFlag),
+	Action: func(c *cli.Context) {
+		action(c, doApply)
+	},
+}
+
+// planAction is a request which 'apply' sends to bring a server to its spec.
+// Action is the name of the command which sends the same request
+type planAction struct {
+	Server  string
+	Action  string
+	Changes []string
+	run     func(c *cli.Context) (interface{}, error)
+}
+
+// serverPlan is the plan of the servers in Pacifile
+type serverPlan struct {
+	Actions  []planAction
+	Warnings []string
+}
+
+// applyResult is a result of planAction
+type applyResult struct {
+	Server  string
+	Action  string
+	Success bool
+	Error   string      `json:",omitempty" toml:",omitempty"`
+	Result  interface{} `json:",omitempty" toml:",omitempty"`
+}
+
+// declaredServers returns the names of the servers in Pacifile. names
+// restricts them and they must be declared
+func declaredServers(names []string) ([]string, error) {
+	if len(names) > 0 {
+		for _, name := range names {
+			if _, ok := conf.Servers[name]; !ok {
+				return nil, errors.New("Couldn't find '" + name + "' in the config file")
+			}
+		}
+		return names, nil
+	}
+	for name := range conf.Servers {
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		return nil, errors.New("There is no server in the config file")
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// makePlan fetches the declared servers in parallel and plans the actions for
+// each of them in the order of the names
+func makePlan(c *cli.Context) serverPlan {
+	names, err := declaredServers(c.Args())
+	assert(err)
+
+	actions := make([][]planAction, len(names))
+	warnings := make([][]string, len(names))
+	errs := make([]error, len(names))
+	runParallel(len(names), c.Int("parallel"), func(i int) {
+		actions[i], warnings[i], errs[i] = planServer(names[i], conf.Servers[names[i]], c.Bool("allow-ip-drop"))
+	})
+	for i, err := range errs {
+		assert(err, names[i])
+	}
+
+	plan := serverPlan{Actions: []planAction{}, Warnings: []string{}}
+	for i := range names {
+		plan.Actions = append(plan.Actions, actions[i]...)
+		plan.Warnings = append(plan.Warnings, warnings[i]...)
+	}
+	return plan
+}
+
+// planServer returns the actions which bring the server to the spec s. IP
+// addresses are dropped only if allowIPDrop is true
+func planServer(vename string, s lib.Server, allowIPDrop bool) ([]planAction, []string, error) {
+	ve, fw, err := fetchVeFirewall(vename)
+	if e, ok := err.(*apiError); ok && e.StatusCode == 404 {
+		return planCreate(vename, s)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	autoscale, err := fetchAutoscale(vename)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var actions []planAction
+	var warnings []string
+	cur := veProfile(ve, lib.Firewall{}, autoscale)
+	if fw != nil {
+		cur.Firewall = firewallLines(*fw)
+	}
+	want := specProfile(s)
+	want.fillUnset(cur)
+
+	if want.Template != cur.Template {
+		warnings = append(warnings, fmt.Sprintf("%s: template: %s -> %s can't be applied without recreating the server", vename, cur.Template, want.Template))
+	}
+
+	a, ok, w := planReconfigure(vename, ve, cur, want, allowIPDrop)
+	if ok {
+		actions = append(actions, a)
+	}
+	warnings = append(warnings, w...)
+	if want.BackupSchedule != cur.BackupSchedule {
+		schedule := want.BackupSchedule
+		actions = append(actions, planAction{
+			Server:  vename,
+			Action:  "backup-schedule-set",
+			Changes: profileChanges(cur, want, "backup-schedule"),
+			run: func(c *cli.Context) (interface{}, error) {
+				resp, err := client.SendRequest("PUT", "/ve/"+vename+"/schedule/"+schedule, nil)
+				if err != nil {
+					return nil, err
+				}
+				return newActionResult(vename, "backup-schedule-set", resp, 202)
+			},
+		})
+	}
+	if !reflect.DeepEqual(want.Firewall, cur.Firewall) {
+		act := "fwmodify"
+		if fw == nil {
+			act = "fwcreate"
+		}
+		actions = append(actions, planAction{
+			Server:  vename,
+			Action:  act,
+			Changes: profileChanges(cur, want, "firewall"),
+			run: func(c *cli.Context) (interface{}, error) {
+				return sendFirewall(vename, act, s.Firewall)
+			},
+		})
+	}
+	if !reflect.DeepEqual(want.Autoscale, cur.Autoscale) {
+		act := "autoscale-update"
+		if autoscale == nil || autoscale.Current == nil {
+			act = "autoscale-create"
+		}
+		actions = append(actions, planAction{
+			Server:  vename,
+			Action:  act,
+			Changes: profileChanges(cur, want, "autoscale"),
+			run: func(c *cli.Context) (interface{}, error) {
+				return sendAutoscale(vename, act, s.AutoscaleRule)
+			},
+		})
+	}
+	return actions, warnings, nil
+}
+
+// planCreate returns the actions which create the server and its firewall and
+// autoscale rules
+func planCreate(vename string, s lib.Server) ([]planAction, []string, error) {
+	if s.Spec == nil {
+		return nil, []string{vename + ": the server doesn't exist and there is no Spec to create it"}, nil
+	}
+	spec := *s.Spec
+	if len(spec.Name) == 0 {
+		spec.Name = vename
+	}
+	if len(spec.Hostname) == 0 {
+		spec.Hostname = spec.Name
+	}
+
+	var changes []string
+	zero := serverProfile{}.fields()
+	for i, f := range specProfile(s).fields() {
+		if !reflect.DeepEqual(f.values, zero[i].values) {
+			for _, v := range f.values {
+				changes = append(changes, f.name+": "+v)
+			}
+		}
+	}
+	actions := []planAction{{
+		Server:  vename,
+		Action:  "create",
+		Changes: changes,
+		run: func(c *cli.Context) (interface{}, error) {
+			pwd, err := createVe(spec)
+			if err != nil {
+				return nil, err
+			}
+			if pwd, err = savePassword(c, vename, passwordAdmin, pwd); err != nil {
+				return pwd, err
+			}
+			return pwd, waitForOperation(vename, waitCreated, newWaitOptions(c))
+		},
+	}}
+	if len(s.Firewall.Rule) > 0 {
+		actions = append(actions, planAction{
+			Server:  vename,
+			Action:  "fwcreate",
+			Changes: firewallLines(s.Firewall),
+			run: func(c *cli.Context) (interface{}, error) {
+				return sendFirewall(vename, "fwcreate", s.Firewall)
+			},
+		})
+	}
+	if len(s.AutoscaleRule) > 0 {
+		actions = append(actions, planAction{
+			Server:  vename,
+			Action:  "autoscale-create",
+			Changes: autoscaleLines(s.AutoscaleRule),
+			run: func(c *cli.Context) (interface{}, error) {
+				return sendAutoscale(vename, "autoscale-create", s.AutoscaleRule)
+			},
+		})
+	}
+	return actions, nil, nil
+}
+
+// planReconfigure returns the action which modifies the resources of the
+// server. The IP addresses to be dropped are the last ones of the server, so
+// they are left with warnings unless allowIPDrop is true
+func planReconfigure(vename string, ve lib.Ve, cur, want serverProfile, allowIPDrop bool) (planAction, bool, []string) {
+	r := lib.ReconfigureVe{}
+	if want.CPUs != cur.CPUs || want.CPUPower != cur.CPUPower {
+		r.ChangeCPU = new(lib.ChangeCPU)
+		if want.CPUs != cur.CPUs {
+			r.ChangeCPU.Number = want.CPUs
+		}
+		if want.CPUPower != cur.CPUPower {
+			r.ChangeCPU.Power = want.CPUPower
+		}
+	}
+	if want.RAMSize != cur.RAMSize {
+		r.RAMSize = want.RAMSize
+	}
+	if want.Bandwidth != cur.Bandwidth {
+		r.Bandwidth = want.Bandwidth
+	}
+	if want.DiskSize != cur.DiskSize {
+		r.PrimaryDiskSize = want.DiskSize
+	}
+
+	changes := profileChanges(cur, want, "cpus", "cpu-power", "ram-size", "bandwidth", "disk-size")
+	var warnings []string
+	// ip returns the request for the number of IP addresses or nil if it's
+	// left as it is
+	ip := func(name string, from, to int, addrs []lib.IPAddr) *lib.ReconfigureIP {
+		if from == to {
+			return nil
+		}
+		ri := reconfigureIP(to-from, addrs)
+		if ri.DropIP != nil && !allowIPDrop {
+			warnings = append(warnings, vename+": "+ipChange(name, from, to, ri)+" requires --allow-ip-drop option")
+			return nil
+		}
+		changes = append(changes, ipChange(name, from, to, ri))
+		return ri
+	}
+	var addrs []lib.IPAddr
+	for _, e := range ve.Network.PublicIP {
+		addrs = append(addrs, e.Address)
+	}
+	r.ReconfigureIPv4 = ip("public-ipv4", cur.PublicIPv4, want.PublicIPv4, addrs)
+	addrs = nil
+	for _, e := range ve.Network.PublicIP6 {
+		addrs = append(addrs, e.Address)
+	}
+	r.ReconfigureIPv6 = ip("public-ipv6", cur.PublicIPv6, want.PublicIPv6, addrs)
+
+	if r == (lib.ReconfigureVe{}) {
+		return planAction{}, false, warnings
+	}
+	return planAction{
+		Server:  vename,
+		Action:  "modify",
+		Changes: changes,
+		run: func(c *cli.Context) (interface{}, error) {
+			res, err := reconfigureVe(vename, "modify", r)
+			if err != nil {
+				return nil, err
+			}
+			return res, waitForOperation(vename, waitSteady, newWaitOptions(c))
+		},
+	}, true, warnings
+}
+
+// reconfigureIP adds n addresses or drops the last -n addresses of addrs
+func reconfigureIP(n int, addrs []lib.IPAddr) *lib.ReconfigureIP {
+	if n > 0 {
+		return &lib.ReconfigureIP{AddIP: &lib.AddIP{Number: n}}
+	}
+	if -n > len(addrs) {
+		n = -len(addrs)
+	}
+	return &lib.ReconfigureIP{DropIP: &lib.DropIP{IP: addrs[len(addrs)+n:]}}
+}
+
+func ipChange(name string, from, to int, r *lib.ReconfigureIP) string {
+	s := fmt.Sprintf("%s: %d -> %d", name, from, to)
+	if r.DropIP != nil {
+		var addrs []string
+		for _, a := range r.DropIP.IP {
+			addrs = append(addrs, a.String())
+		}
+		s += " (drop " + strings.Join(addrs, ",") + ")"
+	}
+	return s
+}
+
+// profileChanges returns the differences of the named fields between p and q.
+// A single value is shown as 'name: from -> to' and the values of a slice are
+// shown as removed and added lines
+func profileChanges(p, q serverProfile, names ...string) []string {
+	var changes []string
+	qf := q.fields()
+	for i, f := range p.fields() {
+		if !containsString(names, f.name) || reflect.DeepEqual(f.values, qf[i].values) {
+			continue
+		}
+		if reflect.ValueOf(p).Field(i).Kind() != reflect.Slice {
+			changes = append(changes, f.name+": "+f.values[0]+" -> "+qf[i].values[0])
+			continue
+		}
+		for _, l := range diffLines(f.values, qf[i].values) {
+			if l.op != ' ' {
+				changes = append(changes, f.name+": "+string(l.op)+" "+l.text)
+			}
+		}
+	}
+	return changes
+}
+
+func containsString(a []string, s string) bool {
+	for _, e := range a {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
+
+func doPlan(c *cli.Context) {
+	plan := makePlan(c)
+	outputResult(c, plan, func(format string) {
+		printPlan(c, plan)
+	})
+}
+
+func printPlan(c *cli.Context, plan serverPlan) {
+	color := useColor(c)
+	creates := 0
+	for _, a := range plan.Actions {
+		mark, col := "~", colorYellow
+		if a.Action == "create" {
+			mark, col = "+", colorGreen
+			creates++
+		}
+		fmt.Println(paintDiff(color, mark+" "+a.Server+" "+a.Action, col))
+		for _, s := range a.Changes {
+			fmt.Println("    " + s)
+		}
+	}
+	for _, w := range plan.Warnings {
+		fmt.Println(paintDiff(color, "! "+w, colorRed))
+	}
+	if len(plan.Actions) == 0 {
+		fmt.Println("No changes. The servers match their specs")
+		return
+	}
+	fmt.Printf("\nPlan: %d to create, %d to change\n", creates, len(plan.Actions)-creates)
+}
+
+func doApply(c *cli.Context) {
+	plan := makePlan(c)
+	for _, w := range plan.Warnings {
+		fmt.Fprintln(os.Stderr, "Warning: "+w)
+	}
+	if len(plan.Actions) == 0 {
+		outputResult(c, []applyResult{}, func(format string) {
+			fmt.Println("No changes. The servers match their specs")
+		})
+		return
+	}
+
+	confirmOrExit(c, "apply the changes", func() []confirmTarget {
+		var targets []confirmTarget
+		for _, a := range plan.Actions {
+			t := confirmTarget{kind: a.Action, name: a.Server, details: a.Changes}
+			if a.Action != "create" {
+				t.production = isProduction(a.Server)
+			}
+			targets = append(targets, t)
+		}
+		return targets
+	})
+
+	results := []applyResult{}
+	failed := false
+	for _, a := range plan.Actions {
+		fmt.Fprintf(os.Stderr, "Applying %s to %s...\n", a.Action, a.Server)
+		v, err := a.run(c)
+		if err == lib.ErrDryRun {
+			continue
+		}
+		r := applyResult{Server: a.Server, Action: a.Action, Success: err == nil, Result: v}
+		if err != nil {
+			r.Error = strings.TrimSpace(err.Error())
+		}
+		results = append(results, r)
+		if err != nil {
+			failed = true
+			break
+		}
+	}
+	if c.Bool("dry-run") {
+		return
+	}
+
+	outputResult(c, results, func(format string) {
+		printSummary(c, []string{"SERVER", "ACTION"}, len(results), func(i int) ([]interface{}, bool, interface{}, string) {
+			r := results[i]
+			return []interface{}{r.Server, r.Action}, r.Success, r.Result, r.Error
+		})
+	})
+	if failed {
+		os.Exit(exitCodeError)
+	}
+}
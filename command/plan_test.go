@@ -0,0 +1,139 @@
+package command
+
+import (
+	"encoding/xml"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/tsukaeru/pacicli/lib"
+)
+
+const testPlanVe = `<ve>
+  <name>web1</name>
+  <network private-ip="10.0.0.1/24">
+    <public-ip id="1" address="192.0.2.1/24"/>
+    <public-ip id="2" address="192.0.2.2/24"/>
+    <public-ip6 id="3" address="2001:db8::1/64"/>
+  </network>
+</ve>`
+
+func TestPlanReconfigure(t *testing.T) {
+	ve := lib.Ve{}
+	if err := xml.Unmarshal([]byte(testPlanVe), &ve); err != nil {
+		t.Fatalf("xml.Unmarshal returned an error: %v", err)
+	}
+	cur := serverProfile{CPUs: 1, CPUPower: 1000, RAMSize: 1024, Bandwidth: 10000, DiskSize: 20, PublicIPv4: 2, PublicIPv6: 1}
+
+	for _, tt := range []struct {
+		name         string
+		change       func(p *serverProfile)
+		allowIPDrop  bool
+		wantOk       bool
+		wantChanges  []string
+		wantWarnings []string
+	}{
+		{
+			"no change",
+			func(p *serverProfile) {},
+			false,
+			false, nil, nil,
+		},
+		{
+			"resources",
+			func(p *serverProfile) { p.CPUs, p.RAMSize, p.DiskSize = 2, 2048, 40 },
+			false,
+			true,
+			[]string{"cpus: 1 -> 2", "ram-size: 1024 -> 2048", "disk-size: 20 -> 40"},
+			nil,
+		},
+		{
+			"cpu power and bandwidth",
+			func(p *serverProfile) { p.CPUPower, p.Bandwidth = 2000, 5000 },
+			false,
+			true,
+			[]string{"cpu-power: 1000 -> 2000", "bandwidth: 10000 -> 5000"},
+			nil,
+		},
+		{
+			"add addresses",
+			func(p *serverProfile) { p.PublicIPv4, p.PublicIPv6 = 3, 2 },
+			false,
+			true,
+			[]string{"public-ipv4: 2 -> 3", "public-ipv6: 1 -> 2"},
+			nil,
+		},
+		{
+			"drop an address without --allow-ip-drop",
+			func(p *serverProfile) { p.PublicIPv4 = 1 },
+			false,
+			false,
+			nil,
+			[]string{"web1: public-ipv4: 2 -> 1 (drop 192.0.2.2/24) requires --allow-ip-drop option"},
+		},
+		{
+			"drop an address with --allow-ip-drop",
+			func(p *serverProfile) { p.PublicIPv4 = 1 },
+			true,
+			true,
+			[]string{"public-ipv4: 2 -> 1 (drop 192.0.2.2/24)"},
+			nil,
+		},
+		{
+			"other changes are kept without --allow-ip-drop",
+			func(p *serverProfile) { p.RAMSize, p.PublicIPv6 = 512, 0 },
+			false,
+			true,
+			[]string{"ram-size: 1024 -> 512"},
+			[]string{"web1: public-ipv6: 1 -> 0 (drop 2001:db8::1/64) requires --allow-ip-drop option"},
+		},
+	} {
+		want := cur
+		tt.change(&want)
+		a, ok, warnings := planReconfigure("web1", ve, cur, want, tt.allowIPDrop)
+		if ok != tt.wantOk {
+			t.Errorf("%s: planReconfigure returned ok = %v, want %v", tt.name, ok, tt.wantOk)
+		}
+		if ok && (a.Server != "web1" || a.Action != "modify") {
+			t.Errorf("%s: planReconfigure returned the action %s of %s, want modify of web1", tt.name, a.Action, a.Server)
+		}
+		if !reflect.DeepEqual(a.Changes, tt.wantChanges) {
+			t.Errorf("%s: planReconfigure returned the changes %q, want %q", tt.name, a.Changes, tt.wantChanges)
+		}
+		if !reflect.DeepEqual(warnings, tt.wantWarnings) {
+			t.Errorf("%s: planReconfigure returned the warnings %q, want %q", tt.name, warnings, tt.wantWarnings)
+		}
+	}
+}
+
+func TestReconfigureIP(t *testing.T) {
+	var addrs []lib.IPAddr
+	for _, s := range []string{"192.0.2.1/24", "192.0.2.2/24", "192.0.2.3/24"} {
+		a, err := lib.NewIPAddr(s)
+		if err != nil {
+			t.Fatalf("NewIPAddr(%q) returned an error: %v", s, err)
+		}
+		addrs = append(addrs, *a)
+	}
+	for _, tt := range []struct {
+		n    int
+		want string
+	}{
+		{2, "add 2"},
+		{-1, "drop 192.0.2.3/24"},
+		{-2, "drop 192.0.2.2/24 192.0.2.3/24"},
+		{-5, "drop 192.0.2.1/24 192.0.2.2/24 192.0.2.3/24"},
+	} {
+		r := reconfigureIP(tt.n, addrs)
+		var got string
+		switch {
+		case r.AddIP != nil && r.DropIP == nil:
+			got = "add " + strconv.Itoa(r.AddIP.Number)
+		case r.DropIP != nil && r.AddIP == nil:
+			got = "drop " + r.DropIP.IP.String()
+		}
+		if got != tt.want {
+			t.Errorf("reconfigureIP(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,244 @@
+package command
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/tsukaeru/pacicli/lib"
+)
+
+// serverProfile is the configuration of a server in the comparable form. It's
+// built from a running server or from a spec in Pacifile. Rules and apps are
+// sorted strings so that the order returned by the API doesn't matter
+type serverProfile struct {
+	CPUs           int      `json:"cpus"`
+	CPUPower       int      `json:"cpu-power"`
+	RAMSize        int      `json:"ram-size"`
+	Bandwidth      int      `json:"bandwidth"`
+	DiskSize       int      `json:"disk-size"`
+	Template       string   `json:"template"`
+	PublicIPv4     int      `json:"public-ipv4"`
+	PublicIPv6     int      `json:"public-ipv6"`
+	BackupSchedule string   `json:"backup-schedule"`
+	Apps           []string `json:"apps"`
+	Firewall       []string `json:"firewall"`
+	Autoscale      []string `json:"autoscale"`
+}
+
+// fetchProfile builds serverProfile of the existing server. The firewall and
+// autoscale rules are fetched separately from the server
+func fetchProfile(vename string) (serverProfile, error) {
+	ve, fw, err := fetchVeFirewall(vename)
+	if err != nil {
+		return serverProfile{}, err
+	}
+	if fw == nil {
+		fw = &lib.Firewall{}
+	}
+	autoscale, err := fetchAutoscale(vename)
+	if err != nil {
+		return serverProfile{}, err
+	}
+	return veProfile(ve, *fw, autoscale), nil
+}
+
+// fetchAutoscale fetches the autoscale rules of the server. It returns nil if
+// the server doesn't have them. The rules embedded in the server details
+// aren't used because they may differ from what this endpoint returns
+func fetchAutoscale(vename string) (*lib.Autoscale, error) {
+	autoscale := lib.Autoscale{}
+	err := getResource("/ve/"+vename+"/autoscale", &autoscale)
+	if e, ok := err.(*apiError); ok && e.StatusCode == 404 {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &autoscale, nil
+}
+
+// fetchVeFirewall fetches the server and its firewall rules. The rules are nil
+// if the server doesn't have a firewall
+func fetchVeFirewall(vename string) (lib.Ve, *lib.Firewall, error) {
+	ve, err := getVe(vename)
+	if err != nil {
+		return ve, nil, err
+	}
+	fw := lib.Firewall{}
+	err = getResource("/ve/"+vename+"/firewall", &fw)
+	if e, ok := err.(*apiError); ok && e.StatusCode == 404 {
+		return ve, nil, nil
+	}
+	if err != nil {
+		return ve, nil, err
+	}
+	return ve, &fw, nil
+}
+
+// veProfile builds serverProfile of the server with its firewall rules and
+// the autoscale rules which fetchAutoscale returns
+func veProfile(ve lib.Ve, fw lib.Firewall, autoscale *lib.Autoscale) serverProfile {
+	p := serverProfile{
+		CPUs:           ve.CPU.Number,
+		CPUPower:       ve.CPU.Power,
+		RAMSize:        ve.RAMSize,
+		Bandwidth:      ve.Bandwidth,
+		DiskSize:       ve.VeDisk.Size,
+		Template:       ve.Platform.TemplateInfo.Name,
+		PublicIPv4:     len(ve.Network.PublicIP),
+		PublicIPv6:     len(ve.Network.PublicIP6),
+		BackupSchedule: ve.BackupSchedule.Name,
+		Apps:           []string{},
+		Firewall:       firewallLines(fw),
+		Autoscale:      []string{},
+	}
+	for _, e := range ve.AppInfo {
+		if e.InstalledOk && len(e.UninstalledAt) == 0 {
+			p.Apps = append(p.Apps, e.AppTemplate)
+		}
+	}
+	sort.Strings(p.Apps)
+	if autoscale != nil && autoscale.Current != nil {
+		p.Autoscale = autoscaleLines(autoscale.Current.AutoscaleRule)
+	}
+	return p
+}
+
+// specProfile builds serverProfile of a server spec in Pacifile. The values
+// which the spec doesn't have are left unset. Please see fillUnset
+func specProfile(s lib.Server) serverProfile {
+	p := serverProfile{}
+	if s.Spec != nil {
+		p.CPUs = s.Spec.CPU.Number
+		p.CPUPower = s.Spec.CPU.Power
+		p.RAMSize = s.Spec.RAMSize
+		p.Bandwidth = s.Spec.Bandwidth
+		p.DiskSize = s.Spec.VeDisk.Size
+		p.Template = s.Spec.Platform.TemplateInfo.Name
+		p.PublicIPv4 = s.Spec.NoOfPublicIP
+		p.PublicIPv6 = s.Spec.NoOfPublicIPv6
+		if s.Spec.BackupSchedule != nil {
+			p.BackupSchedule = s.Spec.BackupSchedule.Name
+		}
+	}
+	if len(s.Firewall.Rule) > 0 {
+		p.Firewall = firewallLines(s.Firewall)
+	}
+	if len(s.AutoscaleRule) > 0 {
+		p.Autoscale = autoscaleLines(s.AutoscaleRule)
+	}
+	return p
+}
+
+// fillUnset copies the values of q into the fields of p which aren't set so
+// that only the values a spec defines are compared
+func (p *serverProfile) fillUnset(q serverProfile) {
+	pv := reflect.ValueOf(p).Elem()
+	qv := reflect.ValueOf(q)
+	for i := 0; i < pv.NumField(); i++ {
+		f := pv.Field(i)
+		if reflect.DeepEqual(f.Interface(), reflect.Zero(f.Type()).Interface()) {
+			f.Set(qv.Field(i))
+		}
+	}
+}
+
+// profileField is a field of serverProfile. A slice field has a value for each
+// element
+type profileField struct {
+	name   string
+	values []string
+}
+
+func (p serverProfile) fields() []profileField {
+	v := reflect.ValueOf(p)
+	t := v.Type()
+	fields := make([]profileField, t.NumField())
+	for i := range fields {
+		fields[i].name = strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		f := v.Field(i)
+		if f.Kind() == reflect.Slice {
+			for j := 0; j < f.Len(); j++ {
+				fields[i].values = append(fields[i].values, fmt.Sprint(f.Index(j).Interface()))
+			}
+		} else {
+			fields[i].values = []string{fmt.Sprint(f.Interface())}
+		}
+	}
+	return fields
+}
+
+// lines returns the profile as the lines like "ram-size: 2048" to be diffed
+func (p serverProfile) lines() []string {
+	var lines []string
+	for _, f := range p.fields() {
+		for _, v := range f.values {
+			lines = append(lines, f.name+": "+v)
+		}
+	}
+	return lines
+}
+
+// patchOp is an operation of JSON Patch (RFC 6902)
+type patchOp struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value"`
+}
+
+// profilePatch returns JSON Patch which changes p into q. A field which
+// differs is replaced as a whole
+func profilePatch(p, q serverProfile) []patchOp {
+	ops := []patchOp{}
+	pv := reflect.ValueOf(p)
+	qv := reflect.ValueOf(q)
+	for i, f := range p.fields() {
+		if !reflect.DeepEqual(pv.Field(i).Interface(), qv.Field(i).Interface()) {
+			ops = append(ops, patchOp{Op: "replace", Path: "/" + f.name, Value: qv.Field(i).Interface()})
+		}
+	}
+	return ops
+}
+
+func firewallLines(fw lib.Firewall) []string {
+	lines := []string{}
+	for _, e := range fw.Rule {
+		nets := make([]string, len(e.RemoteNet))
+		for i, a := range e.RemoteNet {
+			nets[i] = a.String()
+		}
+		lines = append(lines, fmt.Sprintf("%s %s local-port=%d remote-port=%d remote-net=%s",
+			e.Name, e.Protocol, e.LocalPort, e.RemotePort, strings.Join(nets, ",")))
+	}
+	sort.Strings(lines)
+	return lines
+}
+
+func autoscaleLines(rules []lib.AutoscaleRule) []string {
+	lines := []string{}
+	for _, r := range rules {
+		if r.Deleted != nil && *r.Deleted {
+			continue
+		}
+		s := r.Metric
+		if r.Limits != nil {
+			s += fmt.Sprintf(" limits=%d-%d step=%d", r.Limits.Min, r.Limits.Max, r.Limits.Step)
+		}
+		if r.Thresholds != nil {
+			s += thresholdString(" up=", r.Thresholds.Up) + thresholdString(" down=", r.Thresholds.Down)
+		}
+		lines = append(lines, s)
+	}
+	sort.Strings(lines)
+	return lines
+}
+
+func thresholdString(prefix string, t *lib.Threshold) string {
+	if t == nil || t.Threshold == nil {
+		return ""
+	}
+	return prefix + strconv.Itoa(*t.Threshold) + "%/" + strconv.Itoa(t.Period) + "s"
+}
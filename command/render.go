@@ -0,0 +1,134 @@
+package command
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/codegangsta/cli"
+	"github.com/tatsushid/go-prettytable"
+)
+
+// ANSI escape sequences used to color table cells. All color sequences have
+// the same length so that the cells in a column are kept aligned regardless of
+// their colors
+const (
+	colorRed     = "\x1b[31m"
+	colorGreen   = "\x1b[32m"
+	colorYellow  = "\x1b[33m"
+	colorDefault = "\x1b[39m"
+	colorReset   = "\x1b[0m"
+)
+
+// table is the rendering layer over prettytable which all table outputs use.
+// It handles --no-header option and colors the columns which have a colorizer
+// when colored output is enabled
+type table struct {
+	columns    []prettytable.Column
+	rows       [][]interface{}
+	noHeader   bool
+	color      bool
+	colorizers map[int]func(s string) string
+}
+
+func newTable(c *cli.Context, cols ...prettytable.Column) *table {
+	return &table{
+		columns:    cols,
+		noHeader:   c.Bool("no-header"),
+		color:      useColor(c),
+		colorizers: make(map[int]func(s string) string),
+	}
+}
+
+// colorize sets fn which returns a color for a value of the column i. fn can
+// return an empty string not to color the value
+func (t *table) colorize(i int, fn func(s string) string) {
+	t.colorizers[i] = fn
+}
+
+func (t *table) AddRow(cols ...interface{}) {
+	t.rows = append(t.rows, cols)
+}
+
+func (t *table) Print() {
+	cols := make([]prettytable.Column, len(t.columns))
+	copy(cols, t.columns)
+	if t.color {
+		for i := range t.colorizers {
+			if i < len(cols) {
+				cols[i].Header = paint(cols[i].Header, "")
+			}
+		}
+	}
+
+	tbl, err := prettytable.NewTable(cols...)
+	assert(err)
+	tbl.NoHeader = t.noHeader
+	for _, row := range t.rows {
+		if t.color {
+			colored := make([]interface{}, len(row))
+			for i, v := range row {
+				colored[i] = v
+				if fn, ok := t.colorizers[i]; ok {
+					s := fmt.Sprint(v)
+					colored[i] = paint(s, fn(s))
+				}
+			}
+			row = colored
+		}
+		assert(tbl.AddRow(row...))
+	}
+	tbl.Print()
+}
+
+// paint wraps s with color. An empty color means the terminal default color
+func paint(s, color string) string {
+	if len(color) == 0 {
+		color = colorDefault
+	}
+	return color + s + colorReset
+}
+
+// useColor reports whether the output should be colored. NO_COLOR environment
+// variable disables colors unless --color=always is specified
+func useColor(c *cli.Context) bool {
+	switch strings.ToLower(c.String("color")) {
+	case "always":
+		return true
+	case "never":
+		return false
+	case "", "auto":
+	default:
+		displayErrorAndExit("Invalid --color value '" + c.String("color") + "'. It must be one of 'auto', 'always' or 'never'")
+	}
+	if len(os.Getenv("NO_COLOR")) > 0 {
+		return false
+	}
+	return isTerminal(os.Stdout)
+}
+
+// stateColor returns a color for a server or load balancer state
+func stateColor(s string) string {
+	s = strings.ToLower(s)
+	switch {
+	case s == "started" || s == "running" || s == "active":
+		return colorGreen
+	case s == "stopped" || s == "deleted" || s == "failed" || strings.HasSuffix(s, "error"):
+		return colorRed
+	case len(s) > 0:
+		return colorYellow
+	}
+	return ""
+}
+
+// resultColor returns a color for a result value like "ok", "fail", "true" or
+// "false"
+func resultColor(s string) string {
+	switch strings.ToLower(s) {
+	case "ok", "true":
+		return colorGreen
+	case "fail", "false":
+		return colorRed
+	}
+	return ""
+}
@@ -0,0 +1,60 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/codegangsta/cli"
+	"github.com/tsukaeru/pacicli/lib"
+)
+
+// actionResult is a result of a command which changes a resource. It is used
+// to output the result of those commands in a structured way
+type actionResult struct {
+	Resource   string
+	Action     string
+	StatusCode int
+	Accepted   bool
+	NoOp       bool
+	Message    string
+	Timestamp  lib.Timestamp
+}
+
+// newActionResult builds actionResult from an API response. okCode is the HTTP
+// status code which the API returns when it accepts the request. noOpCodes are
+// the status codes which mean the resource has already been in the requested
+// state, like 304 for start and stop. Any other status code is returned as an
+// *apiError
+func newActionResult(resource, act string, resp *lib.Response, okCode int, noOpCodes ...int) (actionResult, error) {
+	r := actionResult{
+		Resource:   resource,
+		Action:     act,
+		StatusCode: resp.StatusCode,
+		Message:    strings.TrimSpace(string(resp.Body)),
+		Timestamp:  lib.Timestamp{Time: time.Now()},
+	}
+	if resp.StatusCode == okCode {
+		r.Accepted = true
+		return r, nil
+	}
+	for _, code := range noOpCodes {
+		if resp.StatusCode == code {
+			r.NoOp = true
+			return r, nil
+		}
+	}
+	return r, &apiError{StatusCode: resp.StatusCode, Body: string(resp.Body)}
+}
+
+func outputActionResult(c *cli.Context, r actionResult) {
+	outputResult(c, r, func(format string) {
+		printActionResult(r)
+	})
+}
+
+func printActionResult(v interface{}) {
+	if r, ok := v.(actionResult); ok {
+		fmt.Println(r.Resource, r.Message)
+	}
+}
@@ -0,0 +1,253 @@
+package command
+
+import (
+	"errors"
+	"log"
+	"net"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/codegangsta/cli"
+	"github.com/tatsushid/go-prettytable"
+	"github.com/tsukaeru/pacicli/lib"
+)
+
+var commandScheduler = cli.Command{
+	Name:  "scheduler",
+	Usage: "Start and stop Containers/Virtual machines on schedules",
+	Description: `
+	This command executes the power schedules defined in '[Schedules.<name>]'
+	sections of Pacifile. A schedule has a cron expression, a time zone, an
+	action which is 'start' or 'stop' and the target servers specified by names,
+	glob patterns or a label selector. Please see conf/Pacifile.example.
+
+	  pacicli scheduler plan -n 20
+	  pacicli scheduler run
+
+	'plan' lists the next actions without executing them. 'run' keeps running
+	and executes the actions at their times. The target servers are resolved
+	every time an action is executed. A request which failed with a network
+	error or a 5xx response is retried --retries times with --retry-interval
+	seconds interval and every result is logged to stderr.
+
+	A local time which DST skips doesn't match the cron expression and a local
+	time which DST repeats matches only for the first time.
+`,
+	Flags: append(CommonFlags, nextFlag, retriesFlag, retryIntervalFlag, parallelFlag, noHeaderFlag),
+	Action: func(c *cli.Context) {
+		action(c, doScheduler)
+	},
+}
+
+// powerSchedule is a schedule in Pacifile with its parsed cron expression and
+// time zone
+type powerSchedule struct {
+	name string
+	lib.Schedule
+	cron *lib.Cron
+	loc  *time.Location
+}
+
+// scheduledAction is an action which a schedule executes at Time
+type scheduledAction struct {
+	Time     time.Time
+	Schedule string
+	Action   string
+	Targets  string
+}
+
+func doScheduler(c *cli.Context) {
+	if len(c.Args()) == 0 {
+		displayWrongNumOfArgsAndExit(c)
+	}
+	schedules, err := loadSchedules()
+	assert(err)
+
+	switch c.Args().Get(0) {
+	case "plan":
+		doSchedulerPlan(c, schedules)
+	case "run":
+		doSchedulerRun(c, schedules)
+	default:
+		displayErrorAndExit("Unknown scheduler command '" + c.Args().Get(0) + "'. It must be 'run' or 'plan'")
+	}
+}
+
+// loadSchedules validates the schedules in Pacifile and returns them sorted by
+// their names
+func loadSchedules() ([]powerSchedule, error) {
+	if len(conf.Schedules) == 0 {
+		return nil, errors.New("There is no schedule in the config file")
+	}
+	names := make([]string, 0, len(conf.Schedules))
+	for name := range conf.Schedules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var schedules []powerSchedule
+	for _, name := range names {
+		s := conf.Schedules[name]
+		if s.Action != "start" && s.Action != "stop" {
+			return nil, errors.New("Invalid Action '" + s.Action + "' of schedule '" + name + "'. It must be 'start' or 'stop'")
+		}
+		if len(s.Servers) == 0 && len(s.Selector) == 0 {
+			return nil, errors.New("Schedule '" + name + "' has neither Servers nor Selector")
+		}
+		if len(s.Selector) > 0 {
+			if _, err := parseSelector(s.Selector); err != nil {
+				return nil, err
+			}
+		}
+		cron, err := lib.ParseCron(s.Cron)
+		if err != nil {
+			return nil, errors.New(err.Error() + " of schedule '" + name + "'")
+		}
+		loc := time.Local
+		if len(s.Timezone) > 0 {
+			if loc, err = time.LoadLocation(s.Timezone); err != nil {
+				return nil, errors.New("Invalid Timezone '" + s.Timezone + "' of schedule '" + name + "'")
+			}
+		}
+		schedules = append(schedules, powerSchedule{name: name, Schedule: s, cron: cron, loc: loc})
+	}
+	return schedules, nil
+}
+
+// next returns the first time after t when the schedule is executed
+func (s powerSchedule) next(t time.Time) time.Time {
+	return s.cron.Next(t.In(s.loc))
+}
+
+func (s powerSchedule) targets() string {
+	targets := strings.Join(s.Servers, ",")
+	if len(s.Selector) > 0 {
+		if len(targets) > 0 {
+			targets += " "
+		}
+		targets += "-l " + s.Selector
+	}
+	return targets
+}
+
+// nextActions returns the first n actions of the schedules after t in time
+// order
+func nextActions(schedules []powerSchedule, t time.Time, n int) []scheduledAction {
+	next := make([]time.Time, len(schedules))
+	for i, s := range schedules {
+		next[i] = s.next(t)
+	}
+	actions := []scheduledAction{}
+	for len(actions) < n {
+		j := -1
+		for i, u := range next {
+			if !u.IsZero() && (j == -1 || u.Before(next[j])) {
+				j = i
+			}
+		}
+		if j == -1 {
+			break
+		}
+		s := schedules[j]
+		actions = append(actions, scheduledAction{next[j], s.name, s.Action, s.targets()})
+		next[j] = s.next(next[j])
+	}
+	return actions
+}
+
+func doSchedulerPlan(c *cli.Context, schedules []powerSchedule) {
+	actions := nextActions(schedules, time.Now(), c.Int("next"))
+	outputResult(c, actions, func(format string) {
+		tbl := newTable(c, []prettytable.Column{
+			{Header: "TIME"}, {Header: "SCHEDULE"}, {Header: "ACTION"}, {Header: "TARGETS"},
+		}...)
+		for _, e := range actions {
+			tbl.AddRow(e.Time.Format("2006-01-02 15:04 MST (Mon)"), e.Schedule, e.Action, e.Targets)
+		}
+		tbl.Print()
+	})
+}
+
+func doSchedulerRun(c *cli.Context, schedules []powerSchedule) {
+	logger := log.New(os.Stderr, "", log.LstdFlags)
+	logger.Printf("scheduler started with %d schedules", len(schedules))
+
+	t := time.Now()
+	for {
+		actions := nextActions(schedules, t, len(schedules))
+		if len(actions) == 0 {
+			logger.Print("no more action is scheduled")
+			return
+		}
+		t = actions[0].Time
+		logger.Printf("next action: %s %s at %s", actions[0].Schedule, actions[0].Action, t.Format("2006-01-02 15:04 MST"))
+		if d := t.Sub(time.Now()); d > 0 {
+			time.Sleep(d)
+		}
+		for _, s := range schedules {
+			if s.next(t.Add(-time.Minute)).Equal(t) {
+				executeSchedule(c, logger, s)
+			}
+		}
+	}
+}
+
+// executeSchedule does the action of the schedule on the target servers. The
+// requests which failed temporarily are retried and the results are logged
+func executeSchedule(c *cli.Context, logger *log.Logger, s powerSchedule) {
+	retries := c.Int("retries")
+	interval := time.Duration(c.Int("retry-interval")) * time.Second
+	retry := func(what string, fn func() error) {
+		for i := 0; ; i++ {
+			err := fn()
+			if err == nil || err == lib.ErrDryRun {
+				return
+			}
+			msg := strings.TrimSpace(err.Error())
+			if i >= retries || !retryable(err) {
+				logger.Printf("%s: %s: failed: %s", s.name, what, msg)
+				return
+			}
+			logger.Printf("%s: %s: failed (attempt %d/%d), retrying: %s", s.name, what, i+1, retries+1, msg)
+			time.Sleep(interval)
+		}
+	}
+
+	var targets []string
+	retry("resolving targets", func() error {
+		var err error
+		targets, err = resolveServers(s.Servers, false, s.Selector)
+		return err
+	})
+	if len(targets) == 0 {
+		logger.Printf("%s: no target server", s.name)
+		return
+	}
+
+	logger.Printf("%s: %s %s", s.name, s.Action, strings.Join(targets, ","))
+	runParallel(len(targets), c.Int("parallel"), func(i int) {
+		retry(s.Action+" "+targets[i], func() error {
+			r, err := startStopVe(targets[i], s.Action)
+			if err == nil {
+				logger.Printf("%s: %s %s", s.name, targets[i], r.Message)
+			}
+			return err
+		})
+	})
+}
+
+// retryable reports whether a failed request may succeed on retry. Transport
+// errors and 5xx responses are retried but 4xx responses and errors like
+// a selector matching no server are permanent
+func retryable(err error) bool {
+	switch e := err.(type) {
+	case *apiError:
+		return e.StatusCode >= 500
+	case *url.Error, net.Error:
+		return true
+	}
+	return false
+}
@@ -3,10 +3,12 @@ package command
 import (
 	"bytes"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/codegangsta/cli"
 	"github.com/tatsushid/go-prettytable"
@@ -21,8 +23,23 @@ var commandList = cli.Command{
 	The command obtains the list of servers owned by the current user. You can
 	use --subscription-id option to list only the servers that belong to a specific
 	subscription.
+
+	The result can be narrowed down on the client side with --filter option. For
+	example, '--filter state=stopped --filter name=web-*' lists only the stopped
+	servers whose name begins with 'web-'. The same option is available in the
+	other list commands like 'lblist', 'imglist', 'oslist', 'applist' and
+	'backup-list'.
+
+	With --wide option, the details of every server like the public IP addresses,
+	CPU, RAM, disk, OS template and load balancer are fetched concurrently and
+	shown together. --parallel option specifies how many servers are fetched at
+	once.
+
+	Labels of the servers defined in Pacifile or the labels file are shown in
+	LABELS column, and --selector option like '-l role=web,env=prod' lists only
+	the servers which have all of the labels.
 `,
-	Flags: append(CommonFlags, subscriptionIDFlag, noHeaderFlag),
+	Flags: append(CommonFlags, showUnknownFlag, filterFlag, selectorFlag, subscriptionIDFlag, noHeaderFlag, wideFlag, parallelFlag, humanFlag, noHumanFlag),
 	Action: func(c *cli.Context) {
 		action(c, doList)
 	},
@@ -33,8 +50,9 @@ var commandStart = cli.Command{
 	Usage: "Start Container/Virtual machine",
 	Description: `
 	This command starts a specific server.
-`,
-	Flags: CommonFlags,
+
+` + bulkHelp,
+	Flags: append(CommonFlags, allFlag, selectorFlag, parallelFlag, waitFlag, waitTimeoutFlag, pollIntervalFlag),
 	Action: func(c *cli.Context) {
 		action(c, doStartStop)
 	},
@@ -45,13 +63,31 @@ var commandStop = cli.Command{
 	Usage: "Stop Container/Virtual machine",
 	Description: `
 	This command stops a specific server.
-`,
-	Flags: CommonFlags,
+
+` + bulkHelp,
+	Flags: append(CommonFlags, allFlag, selectorFlag, parallelFlag, waitFlag, waitTimeoutFlag, pollIntervalFlag),
 	Action: func(c *cli.Context) {
 		action(c, doStartStop)
 	},
 }
 
+var commandRestart = cli.Command{
+	Name:  "restart",
+	Usage: "Restart Container/Virtual machine",
+	Description: `
+	This command restarts a specific server. It stops the server, waits until it
+	is stopped, then starts it and waits until it is running. If the server has
+	already been stopped, it is just started.
+
+	--timeout option specifies how long to wait for the whole sequence.
+
+` + bulkHelp,
+	Flags: append(CommonFlags, allFlag, selectorFlag, parallelFlag, timeoutFlag, pollIntervalFlag),
+	Action: func(c *cli.Context) {
+		action(c, doRestart)
+	},
+}
+
 var commandCreate = cli.Command{
 	Name:  "create",
 	Usage: "Create Container/Virtual machine",
@@ -59,13 +95,28 @@ var commandCreate = cli.Command{
 	This command creates a new server. To create it, a server --setting-file option
 	must be used and all server properties are correctly defined in it.
 
+	Without the setting file, the server spec is read from Pacifile or built from
+	the options like this.
+
+	  pacicli create web1 --template centos-6-x86_64 --cpus 2 --cpu-power 1000 \
+	    --ram 2048 --disk 20 --bandwidth 100000 --ipv4 1 --backup-schedule daily
+
+	The options override the values in the setting file or Pacifile. OS type and
+	technology are taken from the template and the disk size defaults to the
+	minimum size of the template.
+
 	The administrator password for the new server will be automatically generated
 	and displayed as a command result.
 
 	If you have multiple subscriptions, you have to specify the subscription ID
 	in the setting file. If not, it isn't required.
+
+	A name pattern like 'web-{01..10}' or 'web-{a,b}' creates multiple servers
+	from the same spec in parallel. Name and Hostname are derived for each server
+	keeping the domain part of Hostname, and the generated passwords are printed
+	together.
 `,
-	Flags: append(CommonFlags, settingFlag),
+	Flags: append(CommonFlags, settingFlag, templateFlag, cpusFlag, cpuPowerFlag, ramSizeFlag, diskSizeFlag, bandwidthFlag, ipv4Flag, ipv6Flag, backupScheduleFlag, descriptionFlag, parallelFlag, waitFlag, waitTimeoutFlag, pollIntervalFlag, passwordFileFlag, passwordStoreFlag, noShowPasswordFlag),
 	Action: func(c *cli.Context) {
 		action(c, doCreate)
 	},
@@ -85,8 +136,11 @@ var commandCreateFromImage = cli.Command{
 
 	If you have multiple subscriptions, you have to specify the subscription ID
 	by --subscription-id option. If not, it isn't required.
+
+	A name pattern like 'web-{01..10}' creates multiple servers from the image in
+	parallel.
 `,
-	Flags: append(CommonFlags, subscriptionIDFlag),
+	Flags: append(CommonFlags, subscriptionIDFlag, parallelFlag, waitFlag, waitTimeoutFlag, pollIntervalFlag),
 	Action: func(c *cli.Context) {
 		action(c, doCreateFromImage)
 	},
@@ -107,8 +161,11 @@ var commandClone = cli.Command{
 
 	If you have multiple subscriptions, you have to specify the subscription ID
 	by --subscription-id option. If not, it isn't required.
+
+	A name pattern like 'web-{01..10}' as <dst_server_name> creates multiple
+	clones in parallel and the generated passwords are printed together.
 `,
-	Flags: append(CommonFlags, subscriptionIDFlag),
+	Flags: append(CommonFlags, subscriptionIDFlag, parallelFlag, passwordFileFlag, passwordStoreFlag, noShowPasswordFlag),
 	Action: func(c *cli.Context) {
 		action(c, doClone)
 	},
@@ -142,8 +199,9 @@ var commandRecreate = cli.Command{
 	applications installed in the original server will also be dropped from the new
 	server. If you don't specify the argument, all application that are installed in
 	the original server will be installed in the new one.
-`,
-	Flags: append(CommonFlags, templateFlag, dropAppsFlag),
+
+` + confirmHelp,
+	Flags: append(CommonFlags, templateFlag, dropAppsFlag, waitFlag, waitTimeoutFlag, pollIntervalFlag, yesFlag, passwordFileFlag, passwordStoreFlag, noShowPasswordFlag, selectorFlag),
 	Action: func(c *cli.Context) {
 		action(c, doRecreate)
 	},
@@ -160,8 +218,13 @@ var commandModify = cli.Command{
 	To modify it, --setting-file or other configuration options must be used and
 	please remenber you can't use --add-ipv(4|6) and --drop-ipv(4|6) at the same
 	time.
+
+	--cpus, --cpu-power, --ram-size, --bandwidth and --disk-size options accept
+	relative values like '+512', '-10%' or 'x2', which are resolved against the
+	current configuration of the server. The values are checked against the
+	limits of the autoscale rules of the server if they exist.
 `,
-	Flags: append(CommonFlags, settingFlag, cpusFlag, cpuPowerFlag, ramSizeFlag, bandwidthFlag, addIPv4Flag, dropIPv4Flag, addIPv6Flag, dropIPv6Flag, diskSizeFlag, customNsFlag, noCustomNsFlag),
+	Flags: append(CommonFlags, settingFlag, cpusFlag, cpuPowerFlag, ramSizeFlag, bandwidthFlag, addIPv4Flag, dropIPv4Flag, addIPv6Flag, dropIPv6Flag, diskSizeFlag, customNsFlag, noCustomNsFlag, waitFlag, waitTimeoutFlag, pollIntervalFlag, selectorFlag),
 	Action: func(c *cli.Context) {
 		action(c, doModify)
 	},
@@ -173,8 +236,9 @@ var commandResetPassword = cli.Command{
 	Description: `
 	This command resets the server administrator password. The new password will
 	be automatically generated.
-`,
-	Flags: CommonFlags,
+
+` + bulkHelp,
+	Flags: append(CommonFlags, allFlag, selectorFlag, parallelFlag, passwordFileFlag, passwordStoreFlag, noShowPasswordFlag),
 	Action: func(c *cli.Context) {
 		action(c, doResetPassword)
 	},
@@ -187,7 +251,7 @@ var commandInfo = cli.Command{
 	This command obtains the information about the specified server. The
 	<server_name> argument must contain the server name.
 `,
-	Flags: CommonFlags,
+	Flags: append(CommonFlags, showUnknownFlag, selectorFlag),
 	Action: func(c *cli.Context) {
 		action(c, doInfo)
 	},
@@ -205,7 +269,7 @@ var commandHistory = cli.Command{
 	This command must be used with a pair of --from and --to flags datetime
 	arguments or --num-records flag argument.
 `,
-	Flags: append(CommonFlags, numRecordsFlag, fromDatetimeFlag, toDatetimeFlag, verboseFlag, noHeaderFlag),
+	Flags: append(CommonFlags, showUnknownFlag, numRecordsFlag, fromDatetimeFlag, toDatetimeFlag, verboseFlag, noHeaderFlag, humanFlag, noHumanFlag, selectorFlag),
 	Action: func(c *cli.Context) {
 		action(c, doHistory)
 	},
@@ -219,7 +283,7 @@ var commandUsage = cli.Command{
 	the datetime interval, it must be used with a pair of --from and --to flags
 	datetime arguments.
 `,
-	Flags: append(CommonFlags, fromDatetimeFlag, toDatetimeFlag, verboseFlag),
+	Flags: append(CommonFlags, showUnknownFlag, fromDatetimeFlag, toDatetimeFlag, verboseFlag, humanFlag, noHumanFlag, selectorFlag),
 	Action: func(c *cli.Context) {
 		action(c, doUsage)
 	},
@@ -232,8 +296,10 @@ var commandDelete = cli.Command{
 	This command permanently deletes a server. Please note that you can only delete
 	a fully stopped server. If a server is in a transition state (stopping,
 	starting, a disk is being attached to it, etc.), it cannot be deleted.
-`,
-	Flags: CommonFlags,
+
+` + bulkHelp + `
+` + confirmHelp,
+	Flags: append(CommonFlags, allFlag, selectorFlag, parallelFlag, waitFlag, waitTimeoutFlag, pollIntervalFlag, yesFlag),
 	Action: func(c *cli.Context) {
 		action(c, doDelete)
 	},
@@ -249,7 +315,7 @@ var commandInitiatingVnc = cli.Command{
 	obtain them using 'info' command after the command. These will be included in
 	the 'console' part of the 'info' command result.
 `,
-	Flags: CommonFlags,
+	Flags: append(CommonFlags, passwordFileFlag, passwordStoreFlag, noShowPasswordFlag, selectorFlag),
 	Action: func(c *cli.Context) {
 		action(c, doInitiatingVnc)
 	},
@@ -268,47 +334,201 @@ func doList(c *cli.Context) {
 	if resp.StatusCode >= 400 {
 		displayErrorAndExit(string(resp.Body))
 	}
+	applyListFilters(c, &velist.VeInfo)
+	labels, err := serverLabels()
+	assert(err)
+	assert(applySelector(c, &velist, labels))
+
+	if c.Bool("wide") {
+		doListWide(c, velist, labels)
+		return
+	}
 
-	tbl, err := prettytable.NewTable([]prettytable.Column{
+	cols := []prettytable.Column{
 		{Header: "ID", AlignRight: true},
 		{Header: "NAME"},
 		{Header: "HOSTNAME"},
 		{Header: "STATE"},
 		{Header: "SUBSCR_ID", AlignRight: true},
-	}...)
-	assert(err)
+	}
+	showLabels := hasLabels(velist, labels)
+	if showLabels {
+		cols = append(cols, prettytable.Column{Header: "LABELS"})
+	}
+	tbl := newTable(c, cols...)
+	tbl.colorize(3, stateColor)
 
-	outputResult(c, velist, func(format string) {
-		if c.Bool("no-header") {
-			tbl.NoHeader = true
-		}
+	outputResponse(c, resp, velist, func(format string) {
 		for _, e := range velist.VeInfo {
-			tbl.AddRow(e.ID, e.Name, e.Hostname, e.State, e.SubscriptionID)
+			row := []interface{}{e.ID, e.Name, e.Hostname, e.State, e.SubscriptionID}
+			if showLabels {
+				row = append(row, formatLabels(labels[e.Name]))
+			}
+			tbl.AddRow(row...)
+		}
+		tbl.Print()
+	})
+}
+
+// doListWide fetches the details of all servers in velist concurrently and
+// shows them in one table
+func doListWide(c *cli.Context, velist lib.VeList, labels map[string]map[string]string) {
+	ves := make([]lib.Ve, len(velist.VeInfo))
+	errs := make([]error, len(velist.VeInfo))
+	runParallel(len(velist.VeInfo), c.Int("parallel"), func(i int) {
+		ves[i], errs[i] = getVe(velist.VeInfo[i].Name)
+	})
+	// A server which couldn't be fetched is shown with its name and state in
+	// the list and the error is reported after the list
+	failed := false
+	for i, err := range errs {
+		if err != nil {
+			ves[i] = lib.Ve{Name: velist.VeInfo[i].Name, State: velist.VeInfo[i].State}
+			failed = true
+		}
+	}
+
+	outputResult(c, ves, func(format string) {
+		human := humanReadable(c)
+		cols := []prettytable.Column{
+			{Header: "NAME"},
+			{Header: "STATE"},
+			{Header: "IPV4"},
+			{Header: "IPV6"},
+			{Header: unitHeader("CPU", unitMHz, human), AlignRight: true},
+			{Header: unitHeader("RAM", unitMB, human), AlignRight: true},
+			{Header: unitHeader("DISK", unitGB, human), AlignRight: true},
+			{Header: "TEMPLATE"},
+			{Header: "LB"},
+		}
+		showLabels := hasLabels(velist, labels)
+		if showLabels {
+			cols = append(cols, prettytable.Column{Header: "LABELS"})
+		}
+		tbl := newTable(c, cols...)
+		tbl.colorize(1, stateColor)
+		for i, ve := range ves {
+			if errs[i] != nil {
+				row := make([]interface{}, len(cols))
+				row[0], row[1] = ve.Name, ve.State
+				for j := 2; j < len(row); j++ {
+					row[j] = "-"
+				}
+				tbl.AddRow(row...)
+				continue
+			}
+			var ipv4, ipv6 []string
+			for _, e := range ve.Network.PublicIP {
+				ipv4 = append(ipv4, e.Address.IP.String())
+			}
+			for _, e := range ve.Network.PublicIP6 {
+				ipv6 = append(ipv6, e.Address.IP.String())
+			}
+			lb := "-"
+			if len(ve.LoadBalancer) > 0 {
+				lb = ve.LoadBalancer
+			}
+			row := []interface{}{
+				ve.Name,
+				ve.State,
+				strings.Join(ipv4, ","),
+				strings.Join(ipv6, ","),
+				strconv.Itoa(ve.CPU.Number) + "x" + formatUnit(ve.CPU.Power, unitMHz, human),
+				formatUnit(ve.RAMSize, unitMB, human),
+				formatUnit(ve.VeDisk.Size, unitGB, human),
+				ve.Platform.TemplateInfo.Name,
+				lb,
+			}
+			if showLabels {
+				row = append(row, formatLabels(labels[ve.Name]))
+			}
+			tbl.AddRow(row...)
 		}
 		tbl.Print()
 	})
+	if failed {
+		for i, err := range errs {
+			if err != nil {
+				fmt.Fprintln(os.Stderr, velist.VeInfo[i].Name+":", strings.TrimSpace(err.Error()))
+			}
+		}
+		os.Exit(exitCodeError)
+	}
 }
 
 func doStartStop(c *cli.Context) {
-	if len(c.Args()) == 0 {
-		displayWrongNumOfArgsAndExit(c)
+	want := waitRunning
+	if c.Command.Name == "stop" {
+		want = waitStopped
 	}
-	vename := c.Args().Get(0)
+	runBulkWait(c, bulkTargets(c, c.Args()), want, func(vename string) (interface{}, error) {
+		return startStopVe(vename, c.Command.Name)
+	}, printActionResult)
+}
 
-	resp, err := client.SendRequest("PUT", "/ve/"+vename+"/"+c.Command.Name, nil)
-	assert(err)
-	switch resp.StatusCode {
-	case 202:
-		fmt.Println(vename, string(resp.Body))
-	case 304:
+// startStopVe sends a start or stop request to the server. The request for the
+// server which has already been in the requested state results in no-op
+func startStopVe(vename, act string) (actionResult, error) {
+	resp, err := client.SendRequest("PUT", "/ve/"+vename+"/"+act, nil)
+	if err != nil {
+		return actionResult{}, err
+	}
+
+	r, err := newActionResult(vename, act, resp, 202, 304)
+	if err != nil {
+		return r, err
+	}
+	if r.NoOp {
 		s := "started"
-		if c.Command.Name == "stop" {
+		if act == "stop" {
 			s = "stopped"
 		}
-		displayErrorAndExit(vename, "has already", s)
-	default:
-		displayErrorAndExit(string(resp.Body))
+		r.Message = "has already " + s
 	}
+	return r, nil
+}
+
+func doRestart(c *cli.Context) {
+	runBulk(c, bulkTargets(c, c.Args()), func(vename string) (interface{}, error) {
+		return restartVe(c, vename)
+	}, printActionResult)
+}
+
+// restartVe stops the server, waits for it to be stopped, starts it and waits
+// for it to be running. --timeout option limits the time for the whole sequence
+func restartVe(c *cli.Context, vename string) (actionResult, error) {
+	deadline := time.Now().Add(time.Duration(c.Int("timeout")) * time.Second)
+	opts := waitOptions{interval: time.Duration(c.Int("poll-interval")) * time.Second}
+
+	// Both requests are printed on dry run
+	if _, err := startStopVe(vename, "stop"); err == lib.ErrDryRun {
+		startStopVe(vename, "start")
+		return actionResult{}, err
+	} else if err != nil {
+		return actionResult{}, err
+	}
+	opts.timeout = deadline.Sub(time.Now())
+	if err := waitForOperation(vename, waitStopped, opts); err != nil {
+		return actionResult{}, err
+	}
+
+	r, err := startStopVe(vename, "start")
+	if err != nil {
+		return actionResult{}, err
+	}
+	opts.timeout = deadline.Sub(time.Now())
+	if err := waitForOperation(vename, waitRunning, opts); err != nil {
+		return actionResult{}, err
+	}
+
+	return actionResult{
+		Resource:   vename,
+		Action:     "restart",
+		StatusCode: r.StatusCode,
+		Accepted:   true,
+		Message:    "has been restarted",
+		Timestamp:  lib.Timestamp{Time: time.Now()},
+	}, nil
 }
 
 func doCreate(c *cli.Context) {
@@ -316,63 +536,181 @@ func doCreate(c *cli.Context) {
 		displayWrongNumOfArgsAndExit(c)
 	}
 	vename := c.Args().Get(0)
+	names, err := expandNamePattern(vename)
+	assert(err)
+	if len(names) == 1 {
+		vename = names[0]
+	}
 
 	var ve lib.CreateVe
+	fromFlags := false
 	if len(c.String("setting-file")) > 0 {
 		assert(lib.LoadConfig(c.String("setting-file"), &ve))
 		ve.Name = vename
 	} else {
 		if s, ok := conf.Servers[vename]; ok && s.Spec != nil {
 			ve = *s.Spec
+		} else if len(c.String("template")) > 0 {
+			ve.Name = vename
+			ve.VeDisk.Local = true
+			fromFlags = true
 		} else {
 			cli.ShowCommandHelp(c, c.Command.Name)
 			os.Exit(1)
 		}
 	}
+	assert(applyCreateFlags(c, &ve, fromFlags))
+
+	if len(names) > 1 {
+		runBulkWait(c, names, waitCreated, func(name string) (interface{}, error) {
+			v := ve
+			v.Name = name
+			v.Hostname = instanceHostname(ve.Hostname, name)
+			pwd, err := createVe(v)
+			if err != nil {
+				return nil, err
+			}
+			return savePassword(c, name, passwordAdmin, pwd)
+		}, func(v interface{}) {
+			lib.PrintXMLStruct(v)
+		})
+		return
+	}
 
 	if len(ve.Hostname) == 0 {
 		ve.Hostname = ve.Name
 	}
+	pwd, err := createVe(ve)
+	assert(err)
+	pwd, err = savePassword(c, ve.Name, passwordAdmin, pwd)
+	outputPassword(c, pwd, err)
+
+	waitIfRequested(c, ve.Name, waitCreated)
+}
+
+func createVe(ve lib.CreateVe) (lib.PasswordResponse, error) {
+	pwd := lib.PasswordResponse{}
 
 	var b bytes.Buffer
-	assert(xml.NewEncoder(&b).Encode(ve))
+	if err := xml.NewEncoder(&b).Encode(ve); err != nil {
+		return pwd, err
+	}
 
 	resp, err := client.SendRequest("POST", "/ve/", &b)
-	assert(err)
-
+	if err != nil {
+		return pwd, err
+	}
 	if resp.StatusCode >= 400 {
-		displayErrorAndExit(string(resp.Body))
+		return pwd, errors.New(string(resp.Body))
 	}
 
-	pwd := lib.PasswordResponse{}
-	assert(xml.Unmarshal(resp.Body, &pwd))
+	err = xml.Unmarshal(resp.Body, &pwd)
+	return pwd, err
+}
 
-	outputResult(c, pwd, func(format string) {
-		lib.PrintXMLStruct(pwd)
-	})
+// applyCreateFlags overrides the spec with the values of the options. OS type
+// and technology are taken from the template when it's specified by the option
+// or they are missing, and the disk size is validated against the template.
+// The values which the API requires must be given by the options only when
+// fromFlags is true, i.e. there is no setting file or spec in Pacifile
+func applyCreateFlags(c *cli.Context, ve *lib.CreateVe, fromFlags bool) error {
+	if len(c.String("template")) > 0 {
+		ve.Platform.TemplateInfo.Name = c.String("template")
+	}
+	if len(c.String("description")) > 0 {
+		ve.Description = c.String("description")
+	}
+	if n := absoluteOption(c, "cpus"); n > 0 {
+		ve.CPU.Number = n
+	}
+	if n := absoluteOption(c, "cpu-power"); n > 0 {
+		ve.CPU.Power = n
+	}
+	if n := absoluteOption(c, "ram-size"); n > 0 {
+		ve.RAMSize = n
+	}
+	if n := absoluteOption(c, "disk-size"); n > 0 {
+		ve.VeDisk.Size = n
+	}
+	if n := absoluteOption(c, "bandwidth"); n > 0 {
+		ve.Bandwidth = n
+	}
+	if c.Int("ipv4") > 0 {
+		ve.NoOfPublicIP = c.Int("ipv4")
+	}
+	if c.Int("ipv6") > 0 {
+		ve.NoOfPublicIPv6 = c.Int("ipv6")
+	}
+	if len(c.String("backup-schedule")) > 0 {
+		ve.BackupSchedule = &struct {
+			Name string `xml:"name,attr"`
+		}{Name: c.String("backup-schedule")}
+	}
+
+	name := ve.Platform.TemplateInfo.Name
+	osInfo := &ve.Platform.OSInfo
+	if len(name) > 0 {
+		tmpl := lib.Template{}
+		if err := getResource("/template/"+name, &tmpl); err != nil {
+			return err
+		}
+		if len(c.String("template")) > 0 || len(osInfo.Type) == 0 || len(osInfo.Technology) == 0 {
+			osInfo.Type = tmpl.OSType
+			osInfo.Technology = tmpl.Technology
+		}
+		if ve.VeDisk.Size == 0 {
+			ve.VeDisk.Size = tmpl.MinHddSize
+		}
+		if ve.VeDisk.Size < tmpl.MinHddSize {
+			return fmt.Errorf("Disk size %d GB is smaller than the minimum size %d GB of template '%s'", ve.VeDisk.Size, tmpl.MinHddSize, name)
+		}
+	}
+	if !fromFlags {
+		return nil
+	}
+
+	var missing []string
+	for _, e := range []struct {
+		flag string
+		zero bool
+	}{
+		{"--template", len(name) == 0},
+		{"--cpus", ve.CPU.Number == 0},
+		{"--cpu-power", ve.CPU.Power == 0},
+		{"--ram", ve.RAMSize == 0},
+		{"--bandwidth", ve.Bandwidth == 0},
+	} {
+		if e.zero {
+			missing = append(missing, e.flag)
+		}
+	}
+	if len(missing) > 0 {
+		return errors.New("The server spec lacks some values. Please specify " + strings.Join(missing, ", "))
+	}
+	return nil
 }
 
 func doCreateFromImage(c *cli.Context) {
 	if len(c.Args()) < 2 {
 		displayWrongNumOfArgsAndExit(c)
 	}
-	vename := c.Args().Get(0)
+	names, err := expandNamePattern(c.Args().Get(0))
+	assert(err)
 	imgname := c.Args().Get(1)
 
-	path := "/ve"
-	if c.Int("subscription-id") > 0 {
-		path += "/" + strconv.Itoa(c.Int("subscription-id"))
-	}
-	path += "/" + vename + "/from/" + imgname
+	runBulkWait(c, names, waitCreated, func(vename string) (interface{}, error) {
+		path := "/ve"
+		if c.Int("subscription-id") > 0 {
+			path += "/" + strconv.Itoa(c.Int("subscription-id"))
+		}
+		path += "/" + vename + "/from/" + imgname
 
-	resp, err := client.SendRequest("POST", path, nil)
-	assert(err)
-	switch resp.StatusCode {
-	case 202:
-		fmt.Println(vename, string(resp.Body))
-	default:
-		displayErrorAndExit(string(resp.Body))
-	}
+		resp, err := client.SendRequest("POST", path, nil)
+		if err != nil {
+			return nil, err
+		}
+		return newActionResult(vename, c.Command.Name, resp, 202)
+	}, printActionResult)
 }
 
 func doClone(c *cli.Context) {
@@ -380,33 +718,40 @@ func doClone(c *cli.Context) {
 		displayWrongNumOfArgsAndExit(c)
 	}
 	srcve := c.Args().Get(0)
-	destve := c.Args().Get(1)
-
-	path := "/ve/" + srcve + "/clone-to/" + destve
-	if c.Int("subscription-id") > 0 {
-		path += "/for/" + strconv.Itoa(c.Int("subscription-id"))
-	}
-
-	resp, err := client.SendRequest("POST", path, nil)
+	names, err := expandNamePattern(c.Args().Get(1))
 	assert(err)
 
-	if resp.StatusCode >= 400 {
-		displayErrorAndExit(string(resp.Body))
-	}
+	runBulk(c, names, func(destve string) (interface{}, error) {
+		path := "/ve/" + srcve + "/clone-to/" + destve
+		if c.Int("subscription-id") > 0 {
+			path += "/for/" + strconv.Itoa(c.Int("subscription-id"))
+		}
 
-	pwd := lib.PasswordResponse{}
-	assert(xml.Unmarshal(resp.Body, &pwd))
+		resp, err := client.SendRequest("POST", path, nil)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode >= 400 {
+			return nil, errors.New(string(resp.Body))
+		}
 
-	outputResult(c, pwd, func(format string) {
-		lib.PrintXMLStruct(pwd)
+		pwd := lib.PasswordResponse{}
+		if err := xml.Unmarshal(resp.Body, &pwd); err != nil {
+			return nil, err
+		}
+		return savePassword(c, destve, passwordAdmin, pwd)
+	}, func(v interface{}) {
+		lib.PrintXMLStruct(v)
 	})
 }
 
 func doRecreate(c *cli.Context) {
-	if len(c.Args()) == 0 {
+	args := serverArgs(c, 0)
+	if len(args) == 0 {
 		displayWrongNumOfArgsAndExit(c)
 	}
-	vename := c.Args().Get(0)
+	vename := args.Get(0)
+	confirmOrExit(c, "recreate the server and erase all data on it", describeVe(c, vename))
 
 	path := "/ve/" + vename + "/recreate"
 	var q []string
@@ -429,17 +774,18 @@ func doRecreate(c *cli.Context) {
 
 	pwd := lib.PasswordResponse{}
 	assert(xml.Unmarshal(resp.Body, &pwd))
+	pwd, err = savePassword(c, vename, passwordAdmin, pwd)
+	outputPassword(c, pwd, err)
 
-	outputResult(c, pwd, func(format string) {
-		lib.PrintXMLStruct(pwd)
-	})
+	waitIfRequested(c, vename, waitCreated)
 }
 
 func doModify(c *cli.Context) {
-	if len(c.Args()) == 0 {
+	args := serverArgs(c, 0)
+	if len(args) == 0 {
 		displayWrongNumOfArgsAndExit(c)
 	}
-	vename := c.Args().Get(0)
+	vename := args.Get(0)
 
 	var ve lib.ReconfigureVe
 	if len(c.String("setting-file")) > 0 {
@@ -448,23 +794,25 @@ func doModify(c *cli.Context) {
 	if len(c.String("description")) > 0 {
 		ve.Description = c.String("description")
 	}
-	if c.Int("cpus") > 0 {
+	values, err := resolveResourceOptions(c, vename)
+	assert(err)
+	if n, ok := values["cpus"]; ok {
 		if ve.ChangeCPU == nil {
 			ve.ChangeCPU = new(lib.ChangeCPU)
 		}
-		ve.ChangeCPU.Number = c.Int("cpus")
+		ve.ChangeCPU.Number = n
 	}
-	if c.Int("cpu-power") > 0 {
+	if n, ok := values["cpu-power"]; ok {
 		if ve.ChangeCPU == nil {
 			ve.ChangeCPU = new(lib.ChangeCPU)
 		}
-		ve.ChangeCPU.Power = c.Int("cpu-power")
+		ve.ChangeCPU.Power = n
 	}
-	if c.Int("ram-size") > 0 {
-		ve.RAMSize = c.Int("ram-size")
+	if n, ok := values["ram-size"]; ok {
+		ve.RAMSize = n
 	}
-	if c.Int("bandwidth") > 0 {
-		ve.Bandwidth = c.Int("bandwidth")
+	if n, ok := values["bandwidth"]; ok {
+		ve.Bandwidth = n
 	}
 	if c.Int("add-ipv4") > 0 {
 		if ve.ReconfigureIPv4 == nil {
@@ -510,8 +858,8 @@ func doModify(c *cli.Context) {
 			ve.ReconfigureIPv6.DropIP.IP = append(ve.ReconfigureIPv6.DropIP.IP, *a)
 		}
 	}
-	if c.Int("disk-size") > 0 {
-		ve.PrimaryDiskSize = c.Int("disk-size")
+	if n, ok := values["disk-size"]; ok {
+		ve.PrimaryDiskSize = n
 	}
 	if c.Bool("custom-ns") {
 		if ve.CustomNs == nil {
@@ -537,44 +885,58 @@ func doModify(c *cli.Context) {
 		displayErrorAndExit("Invalid modification setting. Both ReconfigureIPv6.AddIP and DropIP can't be specified at same time")
 	}
 
+	r, err := reconfigureVe(vename, c.Command.Name, ve)
+	assert(err)
+	outputActionResult(c, r)
+
+	waitIfRequested(c, vename, waitSteady)
+}
+
+// reconfigureVe sends the modification of the server
+func reconfigureVe(vename, act string, ve lib.ReconfigureVe) (actionResult, error) {
 	var b bytes.Buffer
-	assert(xml.NewEncoder(&b).Encode(ve))
+	if err := xml.NewEncoder(&b).Encode(ve); err != nil {
+		return actionResult{}, err
+	}
 
 	resp, err := client.SendRequest("PUT", "/ve/"+vename, &b)
-	assert(err)
-	switch resp.StatusCode {
-	case 202:
-		fmt.Println(vename, string(resp.Body))
-	default:
-		displayErrorAndExit(string(resp.Body))
+	if err != nil {
+		return actionResult{}, err
 	}
+	return newActionResult(vename, act, resp, 202)
 }
 
 func doResetPassword(c *cli.Context) {
-	if len(c.Args()) == 0 {
-		displayWrongNumOfArgsAndExit(c)
-	}
-	vename := c.Args().Get(0)
+	runBulk(c, bulkTargets(c, c.Args()), func(vename string) (interface{}, error) {
+		pwd, err := resetPassword(vename)
+		if err != nil {
+			return nil, err
+		}
+		return savePassword(c, vename, passwordAdmin, pwd)
+	}, func(v interface{}) {
+		lib.PrintXMLStruct(v)
+	})
+}
 
+func resetPassword(vename string) (lib.PasswordResponse, error) {
+	pwd := lib.PasswordResponse{}
 	resp, err := client.SendRequest("POST", "/ve/"+vename+"/reset-password", nil)
-	assert(err)
+	if err != nil {
+		return pwd, err
+	}
 	if resp.StatusCode >= 400 {
-		displayErrorAndExit(string(resp.Body))
+		return pwd, errors.New(string(resp.Body))
 	}
-
-	pwd := lib.PasswordResponse{}
-	assert(xml.Unmarshal(resp.Body, &pwd))
-
-	outputResult(c, pwd, func(format string) {
-		lib.PrintXMLStruct(pwd)
-	})
+	err = xml.Unmarshal(resp.Body, &pwd)
+	return pwd, err
 }
 
 func doInfo(c *cli.Context) {
-	if len(c.Args()) == 0 {
+	args := serverArgs(c, 0)
+	if len(args) == 0 {
 		displayWrongNumOfArgsAndExit(c)
 	}
-	vename := c.Args().Get(0)
+	vename := args.Get(0)
 
 	resp, err := client.SendRequest("GET", "/ve/"+vename, nil)
 	assert(err)
@@ -585,16 +947,17 @@ func doInfo(c *cli.Context) {
 	ve := lib.Ve{}
 	assert(xml.Unmarshal(resp.Body, &ve))
 
-	outputResult(c, ve, func(format string) {
+	outputResponse(c, resp, ve, func(format string) {
 		lib.PrintXMLStruct(ve)
 	})
 }
 
 func doHistory(c *cli.Context) {
-	if len(c.Args()) == 0 {
+	args := serverArgs(c, 0)
+	if len(args) == 0 {
 		displayWrongNumOfArgsAndExit(c)
 	}
-	vename := c.Args().Get(0)
+	vename := args.Get(0)
 
 	path := "/ve/" + vename + "/history/"
 	if len(c.String("from")) > 0 && len(c.String("to")) > 0 {
@@ -621,37 +984,50 @@ func doHistory(c *cli.Context) {
 	assert(xml.Unmarshal(resp.Body, &hst))
 	assert(err)
 
-	outputResult(c, hst, func(format string) {
+	outputResponse(c, resp, hst, func(format string) {
 		if c.Bool("verbose") {
 			lib.PrintXMLStruct(hst)
 		} else {
-			tbl, err := prettytable.NewTable([]prettytable.Column{
-				{Header: "DATETIME"},
-				{Header: "CPU", AlignRight: true},
-				{Header: "MEMORY", AlignRight: true},
-				{Header: "DISK", AlignRight: true},
-				{Header: "BANDWIDTH", AlignRight: true},
-				{Header: "PUB_IPS", AlignRight: true},
-				{Header: "STATUS"},
-			}...)
-			assert(err)
-			if c.Bool("no-header") {
-				tbl.NoHeader = true
-			}
-			for _, e := range hst.VeSnapshot {
-				ts, _ := e.EventTimestamp.MarshalText()
-				tbl.AddRow(ts, e.CPU, e.RAM, e.LocalDisk, e.Bandwidth, e.NoOfPublicIP, e.State)
-			}
-			tbl.Print()
+			printVeHistoryTable(c, hst)
 		}
 	})
 }
 
+// printVeHistoryTable prints server or load balancer history records in
+// a table
+func printVeHistoryTable(c *cli.Context, hst lib.VeHistory) {
+	human := humanReadable(c)
+	tbl := newTable(c, []prettytable.Column{
+		{Header: "DATETIME"},
+		{Header: unitHeader("CPU", unitMHz, human), AlignRight: true},
+		{Header: unitHeader("MEMORY", unitMB, human), AlignRight: true},
+		{Header: unitHeader("DISK", unitGB, human), AlignRight: true},
+		{Header: unitHeader("BANDWIDTH", unitKbps, human), AlignRight: true},
+		{Header: "PUB_IPS", AlignRight: true},
+		{Header: "STATUS"},
+	}...)
+	tbl.colorize(6, stateColor)
+	for _, e := range hst.VeSnapshot {
+		ts, _ := e.EventTimestamp.MarshalText()
+		tbl.AddRow(
+			ts,
+			formatUnit(e.CPU, unitMHz, human),
+			formatUnit(e.RAM, unitMB, human),
+			formatUnit(e.LocalDisk, unitGB, human),
+			formatUnit(e.Bandwidth, unitKbps, human),
+			e.NoOfPublicIP,
+			e.State,
+		)
+	}
+	tbl.Print()
+}
+
 func doUsage(c *cli.Context) {
-	if len(c.Args()) == 0 {
+	args := serverArgs(c, 0)
+	if len(args) == 0 {
 		displayWrongNumOfArgsAndExit(c)
 	}
-	vename := c.Args().Get(0)
+	vename := args.Get(0)
 
 	if len(c.String("from")) == 0 || len(c.String("to")) == 0 {
 		displayErrorAndExit("This command must be used with a pair of --from and --to flags arguments. Please see '" + c.App.Name + " help " + c.Command.Name + "'")
@@ -675,7 +1051,7 @@ func doUsage(c *cli.Context) {
 	usage := lib.VeResourceUsageReport{}
 	assert(xml.Unmarshal(resp.Body, &usage))
 
-	outputResult(c, usage, func(format string) {
+	outputResponse(c, resp, usage, func(format string) {
 		if c.Bool("verbose") {
 			lib.PrintXMLStruct(usage)
 		} else {
@@ -684,19 +1060,32 @@ func doUsage(c *cli.Context) {
 			fmt.Printf("  From: %s\n", from.Format(lib.DataTimestampFormat))
 			fmt.Printf("    To: %s\n\n", to.Format(lib.DataTimestampFormat))
 
-			tbl, err := prettytable.NewTable([]prettytable.Column{
+			human := humanReadable(c)
+			cols := []prettytable.Column{
 				{Header: "RESOURCE_TYPE"}, {Header: "USAGE", AlignRight: true},
-			}...)
-			assert(err)
+			}
+			if !human {
+				cols = append(cols, prettytable.Column{Header: "UNIT"})
+			}
+			tbl := newTable(c, cols...)
 			for _, e := range usage.ResourceUsage {
 				name := e.ResourceType
 				if len(e.ResourceUsageType) > 0 {
 					name += "(" + e.ResourceUsageType + ")"
 				}
-				tbl.AddRow(name, e.Value)
+				unit := resourceUnit(e.ResourceType)
+				if human {
+					tbl.AddRow(name, formatUnit(e.Value, unit, human))
+				} else {
+					tbl.AddRow(name, e.Value, unit)
+				}
 			}
 			for _, e := range usage.VeTraffic {
-				tbl.AddRow(e.TrafficType, e.Used)
+				if human {
+					tbl.AddRow(e.TrafficType, formatTraffic(e.Used, human))
+				} else {
+					tbl.AddRow(e.TrafficType, e.Used, unitBytes)
+				}
 			}
 			tbl.Print()
 		}
@@ -704,26 +1093,24 @@ func doUsage(c *cli.Context) {
 }
 
 func doDelete(c *cli.Context) {
-	if len(c.Args()) == 0 {
-		displayWrongNumOfArgsAndExit(c)
-	}
-	vename := c.Args().Get(0)
+	targets := bulkTargets(c, c.Args())
+	confirmOrExit(c, "delete the servers permanently", describeVe(c, targets...))
 
-	resp, err := client.SendRequest("DELETE", "/ve/"+vename, nil)
-	assert(err)
-	switch resp.StatusCode {
-	case 202:
-		fmt.Println(vename, string(resp.Body))
-	default:
-		displayErrorAndExit(string(resp.Body))
-	}
+	runBulkWait(c, targets, waitDeleted, func(vename string) (interface{}, error) {
+		resp, err := client.SendRequest("DELETE", "/ve/"+vename, nil)
+		if err != nil {
+			return nil, err
+		}
+		return newActionResult(vename, c.Command.Name, resp, 202)
+	}, printActionResult)
 }
 
 func doInitiatingVnc(c *cli.Context) {
-	if len(c.Args()) == 0 {
+	args := serverArgs(c, 0)
+	if len(args) == 0 {
 		displayWrongNumOfArgsAndExit(c)
 	}
-	vename := c.Args().Get(0)
+	vename := args.Get(0)
 
 	resp, err := client.SendRequest("POST", "/ve/"+vename+"/console", nil)
 	assert(err)
@@ -733,8 +1120,6 @@ func doInitiatingVnc(c *cli.Context) {
 
 	pwd := lib.PasswordResponse{}
 	assert(xml.Unmarshal(resp.Body, &pwd))
-
-	outputResult(c, pwd, func(format string) {
-		lib.PrintXMLStruct(pwd)
-	})
+	pwd, err = savePassword(c, vename, passwordVnc, pwd)
+	outputPassword(c, pwd, err)
 }
@@ -0,0 +1,103 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"syscall"
+
+	"github.com/codegangsta/cli"
+)
+
+var commandSSH = cli.Command{
+	Name:  "ssh",
+	Usage: "Connect to Container/Virtual machine with ssh",
+	Description: `
+	This command connects to the specified server with the system ssh client. The
+	server's first public IPv4 address and its administrator login are used. The
+	arguments after '--' are passed to ssh as a command to run on the server.
+
+	  pacicli ssh web1 -- uptime
+
+	--ipv6 option makes it use the first public IPv6 address instead. --print
+	option only prints the resolved 'user@host' without connecting.
+`,
+	Flags: append(CommonFlags, useIPv6Flag, printFlag, selectorFlag),
+	Action: func(c *cli.Context) {
+		action(c, doSSH)
+	},
+}
+
+// sshTarget is a login user and an address to connect to a server with ssh
+type sshTarget struct {
+	User string
+	Host string
+}
+
+func (t sshTarget) String() string {
+	return t.User + "@" + t.Host
+}
+
+func doSSH(c *cli.Context) {
+	args := serverArgs(c, 0)
+	if len(args) == 0 {
+		displayWrongNumOfArgsAndExit(c)
+	}
+	vename := args.Get(0)
+
+	t, err := resolveSSHTarget(vename, c.Bool("ipv6"))
+	assert(err)
+
+	if c.Bool("print") {
+		outputResult(c, t, func(format string) {
+			fmt.Println(t)
+		})
+		return
+	}
+
+	path, err := exec.LookPath("ssh")
+	assert(err)
+	cmd := exec.Command(path, append([]string{t.String()}, args[1:]...)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		// Exit with the same code as ssh
+		if e, ok := err.(*exec.ExitError); ok {
+			if s, ok := e.Sys().(syscall.WaitStatus); ok {
+				os.Exit(s.ExitStatus())
+			}
+		}
+		assert(err)
+	}
+}
+
+// resolveSSHTarget returns the administrator login and the first public IP
+// address of the server
+func resolveSSHTarget(vename string, ipv6 bool) (sshTarget, error) {
+	ve, err := getVe(vename)
+	if err != nil {
+		return sshTarget{}, err
+	}
+
+	t := sshTarget{User: ve.Admin.Login}
+	if len(t.User) == 0 {
+		return t, errors.New("Couldn't find the administrator login of '" + vename + "'")
+	}
+	if ipv6 {
+		if len(ve.Network.PublicIP6) > 0 {
+			t.Host = ve.Network.PublicIP6[0].Address.IP.String()
+		}
+	} else if len(ve.Network.PublicIP) > 0 {
+		t.Host = ve.Network.PublicIP[0].Address.IP.String()
+	}
+	if len(t.Host) == 0 {
+		kind := "IPv4"
+		if ipv6 {
+			kind = "IPv6"
+		}
+		return t, errors.New("'" + vename + "' doesn't have any public " + kind + " address")
+	}
+	return t, nil
+}
@@ -0,0 +1,184 @@
+package command
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/codegangsta/cli"
+	"github.com/tatsushid/go-prettytable"
+	"github.com/tsukaeru/pacicli/lib"
+)
+
+var commandStatus = cli.Command{
+	Name:  "status",
+	Usage: "Show summary of the account",
+	Description: `
+	This command gathers the servers, load balancers, images and backup
+	schedules of the account in parallel and shows a summary of them. The
+	summary contains the number of servers in each state, the total CPU, RAM,
+	disk and IP addresses allocated to the servers, the servers without a backup
+	schedule, the load balancers with the number of their members and the
+	storage used by images.
+
+	With '-o json' or '-o toml', the summary is printed in the format.
+`,
+	Flags: append(CommonFlags, parallelFlag, humanFlag, noHumanFlag),
+	Action: func(c *cli.Context) {
+		action(c, doStatus)
+	},
+}
+
+// accountStatus is the summary which 'status' command shows
+type accountStatus struct {
+	Servers struct {
+		Total   int
+		ByState map[string]int
+	}
+	Allocated struct {
+		CPUs       int
+		CPUPower   int
+		RAMSize    int
+		DiskSize   int
+		PublicIPv4 int
+		PublicIPv6 int
+	}
+	NoBackupSchedule []string
+	LoadBalancers    []lbStatus
+	Images           struct {
+		Total int
+		Size  int
+	}
+	BackupSchedules int
+}
+
+type lbStatus struct {
+	Name    string
+	State   string
+	Members int
+}
+
+func doStatus(c *cli.Context) {
+	velist := lib.VeList{}
+	lblist := lib.LbList{}
+	imglist := lib.ImageList{}
+	schedules := lib.BackupScheduleList{}
+	lists := []struct {
+		path string
+		v    interface{}
+	}{
+		{"/ve", &velist},
+		{"/load-balancer", &lblist},
+		{"/image", &imglist},
+		{"/schedule", &schedules},
+	}
+	errs := make([]error, len(lists))
+	runParallel(len(lists), c.Int("parallel"), func(i int) {
+		errs[i] = getResource(lists[i].path, lists[i].v)
+	})
+	for i, err := range errs {
+		assert(err, lists[i].path)
+	}
+
+	ves := make([]lib.Ve, len(velist.VeInfo))
+	lbs := make([]lib.LoadBalancer, len(lblist.LoadBalancer))
+	errs = make([]error, len(ves)+len(lbs))
+	runParallel(len(errs), c.Int("parallel"), func(i int) {
+		if i < len(ves) {
+			ves[i], errs[i] = getVe(velist.VeInfo[i].Name)
+		} else {
+			errs[i] = getResource("/load-balancer/"+lblist.LoadBalancer[i-len(ves)].Name, &lbs[i-len(ves)])
+		}
+	})
+	for i, err := range errs {
+		if i < len(ves) {
+			assert(err, velist.VeInfo[i].Name)
+		} else {
+			assert(err, lblist.LoadBalancer[i-len(ves)].Name)
+		}
+	}
+
+	st := accountStatus{}
+	st.Servers.Total = len(velist.VeInfo)
+	st.Servers.ByState = make(map[string]int)
+	for _, e := range velist.VeInfo {
+		st.Servers.ByState[e.State]++
+	}
+	st.NoBackupSchedule = []string{}
+	for _, ve := range ves {
+		st.Allocated.CPUs += ve.CPU.Number
+		st.Allocated.CPUPower += ve.CPU.Number * ve.CPU.Power
+		st.Allocated.RAMSize += ve.RAMSize
+		st.Allocated.DiskSize += ve.VeDisk.Size
+		st.Allocated.PublicIPv4 += len(ve.Network.PublicIP)
+		st.Allocated.PublicIPv6 += len(ve.Network.PublicIP6)
+		if len(ve.BackupSchedule.Name) == 0 {
+			st.NoBackupSchedule = append(st.NoBackupSchedule, ve.Name)
+		}
+	}
+	sort.Strings(st.NoBackupSchedule)
+	st.LoadBalancers = []lbStatus{}
+	for i, e := range lblist.LoadBalancer {
+		st.LoadBalancers = append(st.LoadBalancers, lbStatus{e.Name, e.State, len(lbs[i].UsedBy)})
+	}
+	st.Images.Total = len(imglist.ImageInfo)
+	for _, e := range imglist.ImageInfo {
+		st.Images.Size += e.Size
+	}
+	st.BackupSchedules = len(schedules.BackupSchedule)
+
+	outputResult(c, st, func(format string) {
+		printStatus(c, st)
+	})
+}
+
+func printStatus(c *cli.Context, st accountStatus) {
+	human := humanReadable(c)
+
+	fmt.Printf("Servers: %d\n", st.Servers.Total)
+	states := make([]string, 0, len(st.Servers.ByState))
+	for s := range st.Servers.ByState {
+		states = append(states, s)
+	}
+	sort.Strings(states)
+	tbl := newTable(c, []prettytable.Column{
+		{Header: "STATE"}, {Header: "SERVERS", AlignRight: true},
+	}...)
+	tbl.colorize(0, stateColor)
+	for _, s := range states {
+		tbl.AddRow(s, st.Servers.ByState[s])
+	}
+	tbl.Print()
+
+	a := st.Allocated
+	fmt.Println()
+	fmt.Println("Allocated:")
+	fmt.Printf("  CPU:  %d cores, %s\n", a.CPUs, formatUnit(a.CPUPower, unitMHz, human))
+	fmt.Printf("  RAM:  %s\n", formatUnit(a.RAMSize, unitMB, human))
+	fmt.Printf("  Disk: %s\n", formatUnit(a.DiskSize, unitGB, human))
+	fmt.Printf("  IPv4: %d\n", a.PublicIPv4)
+	fmt.Printf("  IPv6: %d\n", a.PublicIPv6)
+
+	fmt.Println()
+	fmt.Printf("Servers without backup schedule: %d\n", len(st.NoBackupSchedule))
+	for _, s := range st.NoBackupSchedule {
+		fmt.Println("  " + s)
+	}
+
+	fmt.Println()
+	fmt.Printf("Load balancers: %d\n", len(st.LoadBalancers))
+	if len(st.LoadBalancers) > 0 {
+		tbl = newTable(c, []prettytable.Column{
+			{Header: "NAME"}, {Header: "STATE"}, {Header: "MEMBERS", AlignRight: true},
+		}...)
+		tbl.colorize(1, stateColor)
+		for _, e := range st.LoadBalancers {
+			tbl.AddRow(e.Name, e.State, e.Members)
+		}
+		tbl.Print()
+	}
+
+	fmt.Println()
+	fmt.Printf("Images: %d (%s)\n", st.Images.Total, formatUnit(st.Images.Size, unitBytes, human))
+	fmt.Println("Backup schedules: " + strconv.Itoa(st.BackupSchedules))
+}
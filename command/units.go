@@ -0,0 +1,135 @@
+package command
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/codegangsta/cli"
+)
+
+// Units the API uses for the values in its responses
+const (
+	unitMHz   = "MHz"
+	unitMB    = "MB"
+	unitGB    = "GB"
+	unitKbps  = "kbps"
+	unitBytes = "bytes"
+)
+
+// isTerminal reports whether f is connected to a terminal
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// humanReadable reports whether sizes and rates should be printed in human
+// readable units. It is true by default when stdout is a terminal
+func humanReadable(c *cli.Context) bool {
+	if c.Bool("no-human") {
+		return false
+	}
+	return c.Bool("human") || isTerminal(os.Stdout)
+}
+
+// unitHeader returns a column header which states the raw unit of the column
+// values. Human readable values have their own units so the header is left as
+// it is
+func unitHeader(header, unit string, human bool) string {
+	if human {
+		return header
+	}
+	return header + "(" + unit + ")"
+}
+
+// formatUnit formats v which is in the raw API unit like MB, MHz, kbps etc.
+func formatUnit(v int, unit string, human bool) string {
+	if !human {
+		return strconv.Itoa(v)
+	}
+	switch unit {
+	case unitMHz:
+		return formatCPUPower(v)
+	case unitMB:
+		return formatMemorySize(v)
+	case unitGB:
+		// disk sizes are given in GB by the API and shown as they are
+		return strconv.Itoa(v) + " GB"
+	case unitKbps:
+		return formatBandwidth(v)
+	case unitBytes:
+		return formatByteSize(v)
+	}
+	return strconv.Itoa(v)
+}
+
+func formatCPUPower(mhz int) string {
+	if mhz < 1000 {
+		return strconv.Itoa(mhz) + " MHz"
+	}
+	return strconv.FormatFloat(float64(mhz)/1000, 'f', 2, 64) + " GHz"
+}
+
+func formatMemorySize(mb int) string {
+	if mb < 1024 {
+		return strconv.Itoa(mb) + " MiB"
+	}
+	return strconv.FormatFloat(float64(mb)/1024, 'f', 1, 64) + " GiB"
+}
+
+func formatBandwidth(kbps int) string {
+	if kbps < 1000 {
+		return strconv.Itoa(kbps) + " kbps"
+	}
+	return strconv.FormatFloat(float64(kbps)/1000, 'f', 1, 64) + " Mbps"
+}
+
+// formatByteSize formats a size of data like a backup size in binary units
+func formatByteSize(b int) string {
+	units := []string{"B", "KiB", "MiB", "GiB", "TiB"}
+	v := float64(b)
+	i := 0
+	for ; v >= 1024 && i < len(units)-1; i++ {
+		v /= 1024
+	}
+	if i == 0 {
+		return strconv.Itoa(b) + " B"
+	}
+	return fmt.Sprintf("%.1f %s", v, units[i])
+}
+
+// formatTraffic formats an amount of network traffic in bytes. Human readable
+// values are in decimal units
+func formatTraffic(b int, human bool) string {
+	if !human {
+		return strconv.Itoa(b)
+	}
+	units := []string{"B", "KB", "MB", "GB", "TB"}
+	v := float64(b)
+	i := 0
+	for ; v >= 1000 && i < len(units)-1; i++ {
+		v /= 1000
+	}
+	if i == 0 {
+		return strconv.Itoa(b) + " B"
+	}
+	return fmt.Sprintf("%.1f %s", v, units[i])
+}
+
+// resourceUnit guesses the raw unit of a resource usage value from its
+// resource type name
+func resourceUnit(resourceType string) string {
+	t := strings.ToLower(resourceType)
+	switch {
+	case strings.Contains(t, "cpu"):
+		return unitMHz
+	case strings.Contains(t, "ram"), strings.Contains(t, "memory"):
+		return unitMB
+	case strings.Contains(t, "disk"), strings.Contains(t, "hdd"):
+		return unitGB
+	case strings.Contains(t, "bandwidth"):
+		return unitKbps
+	}
+	return ""
+}
@@ -0,0 +1,77 @@
+package command
+
+import "testing"
+
+func TestFormatUnit(t *testing.T) {
+	for _, tt := range []struct {
+		v     int
+		unit  string
+		human bool
+		want  string
+	}{
+		{1500, unitMHz, false, "1500"},
+		{1500, unitMHz, true, "1.50 GHz"},
+		{800, unitMHz, true, "800 MHz"},
+		{512, unitMB, true, "512 MiB"},
+		{1024, unitMB, true, "1.0 GiB"},
+		{3584, unitMB, true, "3.5 GiB"},
+		{50, unitGB, true, "50 GB"},
+		{50, unitGB, false, "50"},
+		{999, unitKbps, true, "999 kbps"},
+		{10000, unitKbps, true, "10.0 Mbps"},
+		{1023, unitBytes, true, "1023 B"},
+		{1536, unitBytes, true, "1.5 KiB"},
+		{5 * 1024 * 1024 * 1024, unitBytes, true, "5.0 GiB"},
+		{42, "", true, "42"},
+	} {
+		if got := formatUnit(tt.v, tt.unit, tt.human); got != tt.want {
+			t.Errorf("formatUnit(%d, %q, %v) = %q, want %q", tt.v, tt.unit, tt.human, got, tt.want)
+		}
+	}
+}
+
+func TestFormatTraffic(t *testing.T) {
+	for _, tt := range []struct {
+		b     int
+		human bool
+		want  string
+	}{
+		{1500, false, "1500"},
+		{999, true, "999 B"},
+		{1500, true, "1.5 KB"},
+		{2500000, true, "2.5 MB"},
+		{3000000000, true, "3.0 GB"},
+	} {
+		if got := formatTraffic(tt.b, tt.human); got != tt.want {
+			t.Errorf("formatTraffic(%d, %v) = %q, want %q", tt.b, tt.human, got, tt.want)
+		}
+	}
+}
+
+func TestUnitHeader(t *testing.T) {
+	if got := unitHeader("RAM", unitMB, false); got != "RAM(MB)" {
+		t.Errorf("unitHeader = %q, want %q", got, "RAM(MB)")
+	}
+	if got := unitHeader("RAM", unitMB, true); got != "RAM" {
+		t.Errorf("unitHeader = %q, want %q", got, "RAM")
+	}
+}
+
+func TestResourceUnit(t *testing.T) {
+	for _, tt := range []struct {
+		typ  string
+		want string
+	}{
+		{"CPU", unitMHz},
+		{"RAM", unitMB},
+		{"Memory", unitMB},
+		{"Disk", unitGB},
+		{"HDD", unitGB},
+		{"Bandwidth", unitKbps},
+		{"IPs", ""},
+	} {
+		if got := resourceUnit(tt.typ); got != tt.want {
+			t.Errorf("resourceUnit(%q) = %q, want %q", tt.typ, got, tt.want)
+		}
+	}
+}
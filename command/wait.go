@@ -0,0 +1,183 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/codegangsta/cli"
+)
+
+// Conditions of a server which the commands can wait for
+const (
+	waitRunning = "running"
+	waitStopped = "stopped"
+	waitDeleted = "deleted"
+	waitCreated = "created"
+	waitSteady  = "steady"
+)
+
+// waitOptions controls how long and how often a server is polled. The interval
+// is doubled after every poll up to maxInterval if maxInterval is longer than
+// interval. The first poll is done after delay
+type waitOptions struct {
+	timeout     time.Duration
+	interval    time.Duration
+	maxInterval time.Duration
+	delay       time.Duration
+}
+
+func newWaitOptions(c *cli.Context) waitOptions {
+	return waitOptions{
+		timeout:  time.Duration(c.Int("wait-timeout")) * time.Second,
+		interval: time.Duration(c.Int("poll-interval")) * time.Second,
+	}
+}
+
+// assertWaitOptions exits if --poll-interval is specified with a value which
+// would make a wait never sleep between polls. It's checked before a command
+// sends any request so that an operation isn't left without the wait
+func assertWaitOptions(c *cli.Context) {
+	if c.IsSet("poll-interval") && c.Int("poll-interval") <= 0 {
+		displayErrorAndExit("--poll-interval must be greater than 0")
+	}
+}
+
+// waitTimeoutError is returned when a server doesn't reach the expected
+// condition in time
+type waitTimeoutError struct {
+	name  string
+	want  string
+	state string
+}
+
+func (e *waitTimeoutError) Error() string {
+	return fmt.Sprintf("Timed out waiting for '%s' to be %s (last state: %s)", e.name, e.want, e.state)
+}
+
+// stateMatches reports whether state is the state of the condition want. It
+// is called only for a server which is in its steady state
+func stateMatches(state, want string) bool {
+	switch want {
+	case waitRunning:
+		return strings.EqualFold(state, "STARTED") || strings.EqualFold(state, "RUNNING") || strings.EqualFold(state, "ACTIVE")
+	case waitStopped:
+		return strings.EqualFold(state, "STOPPED")
+	case waitCreated, waitSteady:
+		return true
+	}
+	return false
+}
+
+// checkVe fetches the server and reports whether it satisfies the condition
+// want. An error is returned if the last operation on the server failed.
+// transited is set once the server is seen in a transitional state and the
+// result code of the last operation is checked only after that, because it
+// may be left by an operation done before this one
+func checkVe(vename, want string, transited *bool) (bool, string, error) {
+	ve, err := getVe(vename)
+	if e, ok := err.(*apiError); ok && e.StatusCode == 404 {
+		switch want {
+		case waitDeleted:
+			return true, "deleted", nil
+		case waitCreated:
+			return false, "not created", nil
+		}
+	}
+	if err != nil {
+		return false, "", err
+	}
+
+	if len(ve.SteadyState) > 0 && !strings.EqualFold(ve.State, ve.SteadyState) {
+		*transited = true
+		return false, ve.State, nil
+	}
+	matched := stateMatches(ve.State, want)
+	if ve.LastOperationRc != 0 && *transited {
+		return false, ve.State, fmt.Errorf("The operation on '%s' failed (state: %s, last-operation-rc: %d)", vename, ve.State, ve.LastOperationRc)
+	}
+	return matched, ve.State, nil
+}
+
+// waitForOperation waits for the server to satisfy the condition want after
+// an operation on it is requested. The server may not be in the transitional
+// state of the operation yet just after the request, which would satisfy
+// 'steady' and 'created' at once, so the first poll for them is delayed for
+// one interval
+func waitForOperation(vename, want string, opts waitOptions) error {
+	if want == waitSteady || want == waitCreated {
+		opts.delay = opts.interval
+	}
+	return waitForVe(vename, want, opts)
+}
+
+// waitForVe polls the server until it satisfies the condition want
+func waitForVe(vename, want string, opts waitOptions) error {
+	transited := false
+	return poll(vename, want, opts, func() (bool, string, error) {
+		return checkVe(vename, want, &transited)
+	})
+}
+
+// poll calls check until it reports the condition is satisfied or fails. It's
+// checked at the deadline for the last time. name and want are only used for
+// the timeout error
+func poll(name, want string, opts waitOptions, check func() (bool, string, error)) error {
+	deadline := time.Now().Add(opts.timeout)
+	interval := opts.interval
+	if opts.delay > 0 {
+		if opts.delay > opts.timeout {
+			opts.delay = opts.timeout
+		}
+		time.Sleep(opts.delay)
+	}
+	for {
+		ok, state, err := check()
+		if err != nil {
+			return err
+		}
+		if ok {
+			return nil
+		}
+		left := deadline.Sub(time.Now())
+		if left <= 0 {
+			return &waitTimeoutError{name: name, want: want, state: state}
+		}
+		// The last sleep is shortened to check once more at the deadline
+		if interval < left {
+			time.Sleep(interval)
+		} else {
+			time.Sleep(left)
+		}
+		if opts.maxInterval > interval {
+			interval *= 2
+			if interval > opts.maxInterval {
+				interval = opts.maxInterval
+			}
+		}
+	}
+}
+
+// assertWait exits with exitCodeTimeout if err is a timeout and with
+// exitCodeError for the other errors
+func assertWait(err error) {
+	if _, ok := err.(*waitTimeoutError); ok {
+		exitWithError(exitCodeTimeout, err)
+	}
+	assert(err)
+}
+
+// waitIfRequested waits for the server to satisfy the condition want when
+// --wait option is specified
+func waitIfRequested(c *cli.Context, vename, want string) {
+	assertWait(requestedWait(c, vename, want))
+}
+
+// requestedWait is the same as waitIfRequested except that it returns an error
+// instead of exiting
+func requestedWait(c *cli.Context, vename, want string) error {
+	if !c.Bool("wait") {
+		return nil
+	}
+	return waitForOperation(vename, want, newWaitOptions(c))
+}
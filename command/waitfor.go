@@ -0,0 +1,156 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/codegangsta/cli"
+	"github.com/tsukaeru/pacicli/lib"
+)
+
+var commandWaitFor = cli.Command{
+	Name:  "wait-for",
+	Usage: "Wait for Container/Virtual machine or load balancer condition",
+	Description: `
+	This command waits until the specified server satisfies the condition. It's
+	useful to wait for an operation which another process started.
+
+	  pacicli wait-for web1 --state running
+	  pacicli wait-for --lb lb1 --state deleted
+	  pacicli wait-for web1 --app mysql --app-state installed
+
+	--state option must be one of 'running', 'stopped', 'deleted', 'created' or
+	'steady'. With --lb option, it waits for the load balancer instead of
+	a server. With --app option, it waits until the application is in the
+	--app-state, 'installed' (default) or 'uninstalled', in the server, and
+	fails if the installation or uninstallation failed.
+
+	The server is polled every --poll-interval seconds and the interval is
+	doubled after every poll up to --max-interval seconds. It exits with 124 if
+	the condition isn't satisfied within --timeout seconds and with 1 if the
+	operation on the server failed.
+`,
+	Flags: append(CommonFlags, stateFlag, lbFlag, appFlag, appStateFlag, timeoutFlag, pollIntervalFlag, maxIntervalFlag, selectorFlag),
+	Action: func(c *cli.Context) {
+		action(c, doWaitFor)
+	},
+}
+
+func doWaitFor(c *cli.Context) {
+	opts := waitOptions{
+		timeout:     time.Duration(c.Int("timeout")) * time.Second,
+		interval:    time.Duration(c.Int("poll-interval")) * time.Second,
+		maxInterval: time.Duration(c.Int("max-interval")) * time.Second,
+	}
+	want := strings.ToLower(c.String("state"))
+
+	var name string
+	var err error
+	switch {
+	case len(c.String("lb")) > 0:
+		name = c.String("lb")
+		if len(want) == 0 {
+			want = waitRunning
+		}
+		assertWaitCondition(want)
+		err = waitForLb(name, want, opts)
+	case len(c.String("app")) > 0:
+		args := serverArgs(c, 0)
+		if len(args) == 0 {
+			displayWrongNumOfArgsAndExit(c)
+		}
+		name = args.Get(0)
+		want = strings.ToLower(c.String("app-state"))
+		if want != "installed" && want != "uninstalled" {
+			displayErrorAndExit("Invalid --app-state value '" + want + "'. It must be 'installed' or 'uninstalled'")
+		}
+		err = waitForApp(name, c.String("app"), want == "installed", opts)
+	default:
+		args := serverArgs(c, 0)
+		if len(args) == 0 {
+			displayWrongNumOfArgsAndExit(c)
+		}
+		name = args.Get(0)
+		if len(want) == 0 {
+			want = waitRunning
+		}
+		assertWaitCondition(want)
+		err = waitForVe(name, want, opts)
+	}
+	assertWait(err)
+
+	outputResult(c, struct {
+		Resource  string
+		Condition string
+		Timestamp lib.Timestamp
+	}{name, want, lib.Timestamp{Time: time.Now()}}, func(format string) {
+		fmt.Println(name, "is", want)
+	})
+}
+
+func assertWaitCondition(want string) {
+	switch want {
+	case waitRunning, waitStopped, waitDeleted, waitCreated, waitSteady:
+		return
+	}
+	displayErrorAndExit("Invalid --state value '" + want + "'. It must be one of 'running', 'stopped', 'deleted', 'created' or 'steady'")
+}
+
+// waitForLb polls the load balancer until it satisfies the condition want
+func waitForLb(lbname, want string, opts waitOptions) error {
+	return poll(lbname, want, opts, func() (bool, string, error) {
+		lb := lib.LoadBalancer{}
+		err := getResource("/load-balancer/"+lbname, &lb)
+		if e, ok := err.(*apiError); ok && e.StatusCode == 404 {
+			switch want {
+			case waitDeleted:
+				return true, "deleted", nil
+			case waitCreated:
+				return false, "not created", nil
+			}
+		}
+		if err != nil {
+			return false, "", err
+		}
+		return stateMatches(lb.State, want), lb.State, nil
+	})
+}
+
+// waitForApp polls the server until the application is installed or
+// uninstalled. The installation or uninstallation has failed if its time is
+// set but it isn't ok
+func waitForApp(vename, appname string, installed bool, opts waitOptions) error {
+	want := "uninstalled"
+	if installed {
+		want = "installed"
+	}
+	return poll(vename+"/"+appname, want, opts, func() (bool, string, error) {
+		ve, err := getVe(vename)
+		if err != nil {
+			return false, "", err
+		}
+		state := "not installed"
+		for _, e := range ve.AppInfo {
+			if e.AppTemplate != appname {
+				continue
+			}
+			switch {
+			case len(e.UninstalledAt) > 0 && e.UninstalledOk:
+				state = "uninstalled"
+			case len(e.UninstalledAt) > 0:
+				return false, "", fmt.Errorf("Uninstalling '%s' from '%s' failed", appname, vename)
+			case e.InstalledOk:
+				state = "installed"
+			case len(e.InstalledAt) > 0:
+				return false, "", fmt.Errorf("Installing '%s' to '%s' failed", appname, vename)
+			default:
+				state = "installing"
+			}
+		}
+		if installed {
+			return state == "installed", state, nil
+		}
+		return state == "uninstalled" || state == "not installed", state, nil
+	})
+}
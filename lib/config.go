@@ -9,16 +9,32 @@ import (
 )
 
 type Config struct {
-	BaseURL  string
-	Username string
-	Password string
-	Servers  map[string]Server
+	BaseURL       string
+	Username      string
+	Password      string
+	LabelsFile    string   // file which maps server names to their labels
+	LoadBalancers []string // load balancers which 'destroy' deletes
+	Images        []string // images which 'destroy' deletes
+	Servers       map[string]Server
+	Schedules     map[string]Schedule
 }
 
 type Server struct {
-	Spec          *CreateVe      // xml struct
-	Firewall      Firewall      // xml struct
-	AutoscaleRule []AutoscaleRule // xml struct
+	Production    bool              // destructive commands require typing the server name
+	Labels        map[string]string // local labels like role = "web"
+	Spec          *CreateVe         // xml struct
+	Firewall      Firewall          // xml struct
+	AutoscaleRule []AutoscaleRule   // xml struct
+}
+
+// Schedule starts or stops servers at the times which its cron expression
+// matches. 'scheduler run' command executes it
+type Schedule struct {
+	Cron     string   // cron expression like "0 9 * * mon-fri"
+	Timezone string   // time zone name like "Asia/Tokyo". Local time if empty
+	Action   string   // "start" or "stop"
+	Servers  []string // server names or glob patterns
+	Selector string   // label selector like "env=dev"
 }
 
 func LoadConfig(fpath string, v interface{}) error {
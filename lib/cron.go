@@ -0,0 +1,194 @@
+package lib
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Cron is a parsed cron expression. It has the standard 5 fields: minute,
+// hour, day of month, month and day of week. Each field is a bit set of the
+// values which match
+type Cron struct {
+	minute, hour, dom, month, dow uint64
+	domStar, dowStar              bool
+}
+
+type cronField struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var cronFields = []cronField{
+	{"minute", 0, 59, nil},
+	{"hour", 0, 23, nil},
+	{"day of month", 1, 31, nil},
+	{"month", 1, 12, map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}},
+	{"day of week", 0, 7, map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}},
+}
+
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// ParseCron parses a cron expression like "0 9 * * mon-fri". A field accepts
+// '*', numbers, ranges like '1-5', lists like '1,3,5' and steps like '*/15'.
+// Month and day of week accept 3 letter names and 7 is also Sunday.
+// Descriptors like '@daily' are accepted too
+func ParseCron(expr string) (*Cron, error) {
+	s := strings.TrimSpace(expr)
+	if d, ok := cronDescriptors[strings.ToLower(s)]; ok {
+		s = d
+	}
+	parts := strings.Fields(s)
+	if len(parts) != len(cronFields) {
+		return nil, errors.New("Invalid cron expression '" + expr + "'. It must have 5 fields")
+	}
+
+	sets := make([]uint64, len(parts))
+	for i, p := range parts {
+		set, err := parseCronField(p, cronFields[i])
+		if err != nil {
+			return nil, errors.New("Invalid cron expression '" + expr + "': " + err.Error())
+		}
+		sets[i] = set
+	}
+	c := &Cron{
+		minute:  sets[0],
+		hour:    sets[1],
+		dom:     sets[2],
+		month:   sets[3],
+		dow:     sets[4],
+		domStar: strings.HasPrefix(parts[2], "*"),
+		dowStar: strings.HasPrefix(parts[4], "*"),
+	}
+	if c.dow&(1<<7) != 0 {
+		c.dow |= 1
+	}
+	return c, nil
+}
+
+func parseCronField(s string, f cronField) (uint64, error) {
+	var set uint64
+	for _, e := range strings.Split(s, ",") {
+		step := 1
+		if i := strings.Index(e, "/"); i != -1 {
+			n, err := strconv.Atoi(e[i+1:])
+			if err != nil || n < 1 {
+				return 0, errors.New("invalid step '" + e + "' in " + f.name)
+			}
+			step = n
+			e = e[:i]
+		}
+
+		var lo, hi int
+		switch i := strings.Index(e, "-"); {
+		case e == "*":
+			lo, hi = f.min, f.max
+		case i != -1:
+			var err error
+			if lo, err = cronValue(e[:i], f); err != nil {
+				return 0, err
+			}
+			if hi, err = cronValue(e[i+1:], f); err != nil {
+				return 0, err
+			}
+		default:
+			var err error
+			if lo, err = cronValue(e, f); err != nil {
+				return 0, err
+			}
+			hi = lo
+			if step > 1 {
+				hi = f.max
+			}
+		}
+		if lo > hi {
+			return 0, errors.New("invalid range '" + e + "' in " + f.name)
+		}
+		for v := lo; v <= hi; v += step {
+			set |= 1 << uint(v)
+		}
+	}
+	return set, nil
+}
+
+func cronValue(s string, f cronField) (int, error) {
+	if v, ok := f.names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < f.min || v > f.max {
+		return 0, errors.New("invalid value '" + s + "' in " + f.name)
+	}
+	return v, nil
+}
+
+// Next returns the first time after t which matches the expression in the
+// location of t. It returns the zero time if there is no such time within
+// 5 years, like '0 0 31 2 *'. A local time which DST skips doesn't match and
+// a local time which DST repeats matches only for the first time
+func (c *Cron) Next(t time.Time) time.Time {
+	loc := t.Location()
+	after := wallClock(t)
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		switch {
+		case c.month&(1<<uint(t.Month())) == 0:
+			t = cronDate(t.Year(), t.Month()+1, 1, 0, loc)
+		case !c.dayMatches(t):
+			t = cronDate(t.Year(), t.Month(), t.Day()+1, 0, loc)
+		case c.hour&(1<<uint(t.Hour())) == 0:
+			t = cronDate(t.Year(), t.Month(), t.Day(), t.Hour()+1, loc)
+		case c.minute&(1<<uint(t.Minute())) == 0, !wallClock(t).After(after):
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// cronDate returns the first time at or after the local time in loc. time.Date
+// returns a time before a DST gap for the local time in the gap, which would
+// make Next go backward
+func cronDate(year int, month time.Month, day, hour int, loc *time.Location) time.Time {
+	want := time.Date(year, month, day, hour, 0, 0, 0, time.UTC)
+	t := time.Date(year, month, day, hour, 0, 0, 0, loc)
+	for wallClock(t).Before(want) {
+		t = t.Add(time.Minute)
+	}
+	return t
+}
+
+// wallClock returns the local time of t as the same clock reading in UTC so
+// that local times can be compared across DST transitions. The hour which
+// DST repeats has the same readings twice
+func wallClock(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, time.UTC)
+}
+
+// dayMatches follows the cron convention. If both day of month and day of week
+// are restricted, a day matching either of them matches
+func (c *Cron) dayMatches(t time.Time) bool {
+	dom := c.dom&(1<<uint(t.Day())) != 0
+	dow := c.dow&(1<<uint(t.Weekday())) != 0
+	if c.domStar || c.dowStar {
+		return dom && dow
+	}
+	return dom || dow
+}
@@ -0,0 +1,160 @@
+package lib
+
+import (
+	"testing"
+	"time"
+)
+
+func mustParseCron(t *testing.T, expr string) *Cron {
+	c, err := ParseCron(expr)
+	if err != nil {
+		t.Fatalf("ParseCron(%q) returned an error: %v", expr, err)
+	}
+	return c
+}
+
+func TestParseCronInvalid(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"* * * foo *",
+		"@reboot",
+	} {
+		if _, err := ParseCron(expr); err == nil {
+			t.Errorf("ParseCron(%q) didn't return an error", expr)
+		}
+	}
+}
+
+func TestCronNext(t *testing.T) {
+	// 2026-10-17 is Saturday
+	from := time.Date(2026, 10, 17, 10, 7, 30, 0, time.UTC)
+	for _, tt := range []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2026, 10, 17, 10, 8, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2026, 10, 17, 10, 15, 0, 0, time.UTC)},
+		{"10-20/5 * * * *", time.Date(2026, 10, 17, 10, 10, 0, 0, time.UTC)},
+		{"5/20 * * * *", time.Date(2026, 10, 17, 10, 25, 0, 0, time.UTC)},
+		{"0,30 9-17 * * *", time.Date(2026, 10, 17, 10, 30, 0, 0, time.UTC)},
+		{"0 9 * * mon-fri", time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)},
+		{"0 9 * * 1-5", time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)},
+		{"0 0 * * SUN", time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 0", time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 jan *", time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 feb *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2026, 10, 17, 11, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)},
+		{"@weekly", time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)},
+		{"@yearly", time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)},
+	} {
+		if got := mustParseCron(t, tt.expr).Next(from); !got.Equal(tt.want) {
+			t.Errorf("%q: Next(%v) = %v, want %v", tt.expr, from, got, tt.want)
+		}
+	}
+}
+
+func TestCronNextDayOfMonthOrDayOfWeek(t *testing.T) {
+	// 2026-10-18 is Sunday
+	from := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	for _, tt := range []struct {
+		expr string
+		want time.Time
+	}{
+		// both are restricted, so either of them matches
+		{"0 0 13 * fri", time.Date(2026, 10, 23, 0, 0, 0, 0, time.UTC)},
+		{"0 0 20 * fri", time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC)},
+		// only one of them is restricted, so it must match
+		{"0 0 13 * *", time.Date(2026, 11, 13, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * fri", time.Date(2026, 10, 23, 0, 0, 0, 0, time.UTC)},
+		// a field starting with '*' is unrestricted even with a step
+		{"0 0 */2 * fri", time.Date(2026, 10, 23, 0, 0, 0, 0, time.UTC)},
+	} {
+		if got := mustParseCron(t, tt.expr).Next(from); !got.Equal(tt.want) {
+			t.Errorf("%q: Next(%v) = %v, want %v", tt.expr, from, got, tt.want)
+		}
+	}
+}
+
+func TestCronNextNever(t *testing.T) {
+	from := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	if got := mustParseCron(t, "0 0 31 2 *").Next(from); !got.IsZero() {
+		t.Errorf("Next(%v) = %v, want the zero time", from, got)
+	}
+}
+
+func TestCronNextDST(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("time zone database isn't available:", err)
+	}
+	// DST starts at 2026-03-08 02:00 EST and ends at 2026-11-01 02:00 EDT
+	for _, tt := range []struct {
+		expr string
+		from time.Time
+		want []time.Time
+	}{
+		{
+			// spring-forward: 02:30 doesn't exist on the day
+			"30 2 * * *",
+			time.Date(2026, 3, 7, 12, 0, 0, 0, loc),
+			[]time.Time{
+				time.Date(2026, 3, 9, 2, 30, 0, 0, loc),
+			},
+		},
+		{
+			"0 3 * * *",
+			time.Date(2026, 3, 7, 12, 0, 0, 0, loc),
+			[]time.Time{
+				time.Date(2026, 3, 8, 3, 0, 0, 0, loc),
+				time.Date(2026, 3, 9, 3, 0, 0, 0, loc),
+			},
+		},
+		{
+			// fall-back: 01:00 is repeated but matches only once
+			"0 1 * * *",
+			time.Date(2026, 10, 31, 12, 0, 0, 0, loc),
+			[]time.Time{
+				time.Date(2026, 11, 1, 5, 0, 0, 0, time.UTC),
+				time.Date(2026, 11, 2, 6, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			"30 * * * *",
+			time.Date(2026, 11, 1, 0, 45, 0, 0, loc),
+			[]time.Time{
+				time.Date(2026, 11, 1, 5, 30, 0, 0, time.UTC),
+				time.Date(2026, 11, 1, 7, 30, 0, 0, time.UTC),
+			},
+		},
+		{
+			"0 3 * * *",
+			time.Date(2026, 10, 31, 12, 0, 0, 0, loc),
+			[]time.Time{
+				time.Date(2026, 11, 1, 3, 0, 0, 0, loc),
+				time.Date(2026, 11, 2, 3, 0, 0, 0, loc),
+			},
+		},
+	} {
+		c := mustParseCron(t, tt.expr)
+		got := tt.from
+		for _, want := range tt.want {
+			got = c.Next(got)
+			if !got.Equal(want) {
+				t.Errorf("%q: Next = %v, want %v", tt.expr, got, want.In(loc))
+				break
+			}
+		}
+	}
+}
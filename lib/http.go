@@ -2,6 +2,7 @@ package lib
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -21,10 +22,18 @@ func (r *Response) String() string {
 	return fmt.Sprintf("Status: %s, Body:\n%s\n", r.Status, r.Body)
 }
 
+// ErrDryRun is returned by SendRequest instead of sending a request which
+// changes a resource when DryRun is set
+var ErrDryRun = errors.New("dry run")
+
 type Client struct {
 	baseURL  string
 	username string
 	password string
+
+	// DryRun is called with the request instead of sending it if the request
+	// changes a resource. GET requests are always sent
+	DryRun func(method, url string, body []byte)
 }
 
 func NewClient(baseURL, username, password string) *Client {
@@ -43,6 +52,19 @@ func (c *Client) SendRequest(method, path string, data io.Reader) (*Response, er
 		}
 	}
 
+	if c.DryRun != nil && method != "GET" {
+		var body []byte
+		if data != nil {
+			b, err := ioutil.ReadAll(data)
+			if err != nil {
+				return nil, err
+			}
+			body = b
+		}
+		c.DryRun(method, c.baseURL+path, body)
+		return nil, ErrDryRun
+	}
+
 	req, err := http.NewRequest(method, c.baseURL+path, data)
 	if err != nil {
 		return nil, err
@@ -1,8 +1,11 @@
 package lib
 
 import (
+	"bytes"
 	"encoding"
+	"encoding/xml"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"reflect"
@@ -18,8 +21,11 @@ func debug(v ...interface{}) {
 var (
 	printXMLStructOffset = 2
 	textMarshalerType    = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	textUnmarshalerType  = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
 )
 
+const indentXMLOffset = "  "
+
 func PrintXMLStruct(s interface{}, indent ...int) {
 	sv := reflect.ValueOf(s)
 	st := sv.Type()
@@ -117,3 +123,235 @@ func PrintXMLStruct(s interface{}, indent ...int) {
 		}
 	}
 }
+
+// IndentXML reformats the XML document b to be human readable. Elements which
+// have no child element are written in one line.
+func IndentXML(b []byte) ([]byte, error) {
+	var toks []xml.Token
+	d := xml.NewDecoder(bytes.NewReader(b))
+	for {
+		tok, err := d.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if cd, ok := tok.(xml.CharData); ok && len(bytes.TrimSpace(cd)) == 0 {
+			continue
+		}
+		toks = append(toks, xml.CopyToken(tok))
+	}
+
+	var buf bytes.Buffer
+	depth := 0
+	newline := func() {
+		if buf.Len() > 0 {
+			buf.WriteByte('\n')
+		}
+		buf.WriteString(strings.Repeat(indentXMLOffset, depth))
+	}
+	for i := 0; i < len(toks); i++ {
+		switch t := toks[i].(type) {
+		case xml.StartElement:
+			newline()
+			buf.WriteString("<" + rawXMLName(t.Name))
+			for _, a := range t.Attr {
+				buf.WriteString(" " + rawXMLName(a.Name) + `="`)
+				xml.EscapeText(&buf, []byte(a.Value))
+				buf.WriteString(`"`)
+			}
+			if i+1 < len(toks) {
+				if _, ok := toks[i+1].(xml.EndElement); ok {
+					buf.WriteString("/>")
+					i++
+					continue
+				}
+			}
+			buf.WriteString(">")
+			if i+2 < len(toks) {
+				cd, ok := toks[i+1].(xml.CharData)
+				if _, end := toks[i+2].(xml.EndElement); ok && end {
+					xml.EscapeText(&buf, bytes.TrimSpace(cd))
+					buf.WriteString("</" + rawXMLName(t.Name) + ">")
+					i += 2
+					continue
+				}
+			}
+			depth++
+		case xml.EndElement:
+			depth--
+			newline()
+			buf.WriteString("</" + rawXMLName(t.Name) + ">")
+		case xml.CharData:
+			newline()
+			xml.EscapeText(&buf, bytes.TrimSpace(t))
+		case xml.Comment:
+			newline()
+			buf.WriteString("<!--" + string(t) + "-->")
+		case xml.ProcInst:
+			newline()
+			buf.WriteString("<?" + t.Target + " " + string(t.Inst) + "?>")
+		case xml.Directive:
+			newline()
+			buf.WriteString("<!" + string(t) + ">")
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+func rawXMLName(n xml.Name) string {
+	if len(n.Space) > 0 {
+		return n.Space + ":" + n.Local
+	}
+	return n.Local
+}
+
+// UnknownXMLFields returns the paths of the XML elements and attributes in b
+// which aren't mapped to any field of v when b is unmarshaled into v.
+// Attribute paths have '@' prefix in their last part like "ve/cpu/@number"
+func UnknownXMLFields(b []byte, v interface{}) ([]string, error) {
+	type frame struct {
+		path string
+		typ  reflect.Type // nil if the element itself isn't mapped
+	}
+
+	var unknown []string
+	seen := make(map[string]bool)
+	report := func(path string) {
+		if !seen[path] {
+			seen[path] = true
+			unknown = append(unknown, path)
+		}
+	}
+
+	var stack []frame
+	d := xml.NewDecoder(bytes.NewReader(b))
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			var f frame
+			if len(stack) == 0 {
+				f = frame{path: t.Name.Local, typ: xmlElemType(reflect.TypeOf(v))}
+			} else {
+				parent := stack[len(stack)-1]
+				f.path = parent.path + "/" + t.Name.Local
+				switch {
+				case parent.typ == nil:
+				case isXMLLeaf(parent.typ):
+					report(f.path)
+				default:
+					if sf, ok := xmlField(parent.typ, t.Name.Local, false); ok {
+						f.typ = xmlElemType(sf.Type)
+					} else if !hasXMLAny(parent.typ, false) {
+						report(f.path)
+					}
+				}
+			}
+			if f.typ != nil {
+				for _, a := range t.Attr {
+					if a.Name.Space == "xmlns" || a.Name.Local == "xmlns" {
+						continue
+					}
+					if isXMLLeaf(f.typ) {
+						report(f.path + "/@" + a.Name.Local)
+					} else if _, ok := xmlField(f.typ, a.Name.Local, true); !ok && !hasXMLAny(f.typ, true) {
+						report(f.path + "/@" + a.Name.Local)
+					}
+				}
+			}
+			stack = append(stack, f)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		}
+	}
+	return unknown, nil
+}
+
+// xmlElemType returns the type which is used to unmarshal a single XML
+// element into the field of type t
+func xmlElemType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr || (t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8) {
+		if t.Kind() == reflect.Slice && reflect.PtrTo(t).Implements(textUnmarshalerType) {
+			break
+		}
+		t = t.Elem()
+	}
+	return t
+}
+
+func isXMLLeaf(t reflect.Type) bool {
+	return t.Kind() != reflect.Struct || reflect.PtrTo(t).Implements(textUnmarshalerType)
+}
+
+func xmlField(t reflect.Type, name string, attr bool) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Name == "XMLName" || (len(f.PkgPath) > 0 && !f.Anonymous) {
+			continue
+		}
+		tag := f.Tag.Get("xml")
+		if tag == "-" {
+			continue
+		}
+		if f.Anonymous && len(tag) == 0 {
+			if et := xmlElemType(f.Type); et.Kind() == reflect.Struct {
+				if sf, ok := xmlField(et, name, attr); ok {
+					return sf, true
+				}
+			}
+			continue
+		}
+		tname, flags := parseXMLTag(tag)
+		if flags["chardata"] || flags["innerxml"] || flags["comment"] || flags["any"] || flags["attr"] != attr {
+			continue
+		}
+		if len(tname) == 0 {
+			tname = f.Name
+			if et := xmlElemType(f.Type); !attr && et.Kind() == reflect.Struct {
+				if xn, ok := et.FieldByName("XMLName"); ok {
+					if n, _ := parseXMLTag(xn.Tag.Get("xml")); len(n) > 0 {
+						tname = n
+					}
+				}
+			}
+		}
+		if i := strings.Index(tname, ">"); i != -1 {
+			tname = tname[:i]
+		}
+		if tname == name {
+			return f, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+func hasXMLAny(t reflect.Type, attr bool) bool {
+	for i := 0; i < t.NumField(); i++ {
+		_, flags := parseXMLTag(t.Field(i).Tag.Get("xml"))
+		if flags["any"] && flags["attr"] == attr {
+			return true
+		}
+	}
+	return false
+}
+
+func parseXMLTag(tag string) (string, map[string]bool) {
+	flags := make(map[string]bool)
+	parts := strings.Split(tag, ",")
+	for _, e := range parts[1:] {
+		flags[e] = true
+	}
+	if i := strings.Index(parts[0], " "); i != -1 {
+		// drop namespace part of "namespace-URL name"
+		parts[0] = parts[0][i+1:]
+	}
+	return parts[0], flags
+}
@@ -0,0 +1,113 @@
+package lib
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestIndentXML(t *testing.T) {
+	for _, tt := range []struct {
+		in   string
+		want string
+	}{
+		{
+			`<ve><name>web1</name><cpu number="2" power="1000"/></ve>`,
+			"<ve>\n  <name>web1</name>\n  <cpu number=\"2\" power=\"1000\"/>\n</ve>",
+		},
+		{
+			"<?xml version=\"1.0\"?>\n<ve-list>\n  <ve-info name=\"a\"></ve-info>\n</ve-list>\n",
+			"<?xml version=\"1.0\"?>\n<ve-list>\n  <ve-info name=\"a\"/>\n</ve-list>",
+		},
+		{
+			// text is trimmed and escaped
+			`<error>  a &lt; b  </error>`,
+			"<error>a &lt; b</error>",
+		},
+		{
+			`<a><b><c>1</c></b><!-- note --></a>`,
+			"<a>\n  <b>\n    <c>1</c>\n  </b>\n  <!-- note -->\n</a>",
+		},
+		{
+			`<ns:a xmlns:ns="urn:x"><ns:b>1</ns:b></ns:a>`,
+			"<ns:a xmlns:ns=\"urn:x\">\n  <ns:b>1</ns:b>\n</ns:a>",
+		},
+	} {
+		got, err := IndentXML([]byte(tt.in))
+		if err != nil {
+			t.Errorf("IndentXML(%q) returned an error: %v", tt.in, err)
+			continue
+		}
+		if string(got) != tt.want {
+			t.Errorf("IndentXML(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestIndentXMLInvalid(t *testing.T) {
+	for _, in := range []string{
+		`<a`,
+		`<a attr=1></a>`,
+	} {
+		if _, err := IndentXML([]byte(in)); err == nil {
+			t.Errorf("IndentXML(%q) didn't return an error", in)
+		}
+	}
+}
+
+type testUnknownCPU struct {
+	Number int `xml:"number,attr"`
+}
+
+type testUnknownVe struct {
+	Name  string           `xml:"name"`
+	CPU   testUnknownCPU   `xml:"cpu"`
+	Disk  []testUnknownCPU `xml:"disk"`
+	State *string          `xml:"state"`
+	Time  Timestamp        `xml:"time"`
+}
+
+type testUnknownAny struct {
+	Name  string `xml:"name"`
+	Other []struct {
+		XMLName struct{} `xml:""`
+	} `xml:",any"`
+}
+
+func TestUnknownXMLFields(t *testing.T) {
+	for _, tt := range []struct {
+		in   string
+		v    interface{}
+		want []string
+	}{
+		{
+			`<ve><name>a</name><cpu number="1"/><disk number="1"/><disk number="2"/><state>ok</state></ve>`,
+			&testUnknownVe{},
+			nil,
+		},
+		{
+			`<ve id="1"><name>a</name><cpu number="1" power="2"><x/></cpu><extra><y/></extra><extra/></ve>`,
+			&testUnknownVe{},
+			[]string{"ve/@id", "ve/cpu/@power", "ve/cpu/x", "ve/extra"},
+		},
+		{
+			// a leaf doesn't have children or attributes
+			`<ve><name lang="en">a</name><time zone="utc"><z/></time></ve>`,
+			&testUnknownVe{},
+			[]string{"ve/name/@lang", "ve/time/@zone", "ve/time/z"},
+		},
+		{
+			`<ve xmlns="urn:x"><name>a</name><extra/></ve>`,
+			&testUnknownAny{},
+			nil,
+		},
+	} {
+		got, err := UnknownXMLFields([]byte(tt.in), tt.v)
+		if err != nil {
+			t.Errorf("UnknownXMLFields(%q) returned an error: %v", tt.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("UnknownXMLFields(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
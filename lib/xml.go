@@ -347,7 +347,7 @@ type ImageList struct {
 		Created        Timestamp `xml:"created,attr"`
 		SubscriptionID int       `xml:"subscription-id,attr"`
 		ImageOf        string    `xml:"image-of,attr"`
-		Location       string    `xml:location,attr"`
+		Location       string    `xml:"location,attr"`
 	} `xml:"image-info"`
 }
 